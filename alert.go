@@ -0,0 +1,49 @@
+package logger
+
+import "time"
+
+// defaultAlertCooldown 是未设置 AlertCooldown 时同一签名两次告警之间的最短间隔
+const defaultAlertCooldown = time.Minute
+
+// maxAlertSignatures 是 alertState 记住的签名数上限，超过后清理已过冷却期的签名
+const maxAlertSignatures = 1024
+
+// alertState 记录每个错误签名最近一次触发 OnErrorAlert 的时间，只在 write() 中访问
+type alertState struct {
+	last map[string]time.Time
+}
+
+// DefaultAlertSignature 是 AlertSignature 的默认值：调用位置与消息相同的错误视为同一种
+func DefaultAlertSignature(r LogRecord) string {
+	return r.Caller + "\x00" + r.Message
+}
+
+// alert 对 ERROR 及以上的日志按签名去重后调用 OnErrorAlert，同一签名在 AlertCooldown 内最多一次
+func (l *Logger) alert(msg logMsg) {
+	rec := msg.record()
+	sig := l.config.AlertSignature
+	if sig == nil {
+		sig = DefaultAlertSignature
+	}
+	cooldown := l.config.AlertCooldown
+	if cooldown <= 0 {
+		cooldown = defaultAlertCooldown
+	}
+	key := sig(rec)
+	if last, ok := l.alerts.last[key]; ok && msg.Time.Sub(last) < cooldown {
+		return
+	}
+	if l.alerts.last == nil {
+		l.alerts.last = make(map[string]time.Time)
+	}
+	if len(l.alerts.last) >= maxAlertSignatures {
+		for k, t := range l.alerts.last {
+			if msg.Time.Sub(t) >= cooldown {
+				delete(l.alerts.last, k)
+			}
+		}
+	}
+	l.alerts.last[key] = msg.Time
+	rec.Fields = l.maskFields(rec.Fields)
+	l.config.OnErrorAlert(rec)
+}
@@ -0,0 +1,42 @@
+package logger
+
+import "slices"
+
+// AddAllowedPrefix 在运行中向 AllowedPrefix 追加一项，已存在时不做任何事。
+// 与 Reconfigure 一样在写完已入队的日志后生效：调用返回后记录的匹配日志写入白名单文件，之前的不受影响。
+// 启动时未配置白名单的 Logger 会在此时打开 logs_allowed/allowed.log。
+func (l *Logger) AddAllowedPrefix(p string) {
+	l.editAllowed(func(prefixes []string) []string {
+		if slices.Contains(prefixes, p) {
+			return prefixes
+		}
+		return append(slices.Clone(prefixes), p)
+	})
+}
+
+// RemoveAllowedPrefix 在运行中从 AllowedPrefix 删除一项，不存在时不做任何事
+func (l *Logger) RemoveAllowedPrefix(p string) {
+	l.editAllowed(func(prefixes []string) []string {
+		return slices.DeleteFunc(slices.Clone(prefixes), func(s string) bool { return s == p })
+	})
+}
+
+func (l *Logger) editAllowed(edit func([]string) []string) {
+	if l.nop {
+		return
+	}
+	_ = l.control(controlReq{allowed: edit})
+}
+
+// applyAllowed 只在 start() 中调用；config 的修改需要持有写锁
+func (l *Logger) applyAllowed(edit func([]string) []string) {
+	prefixes := edit(l.config.AllowedPrefix)
+	w := l.allowFileLogger
+	if len(prefixes) > 0 && w == nil {
+		w = newFileWriter(allowedLogPath, l.config.AllowedRotation)
+	}
+	l.mu.Lock()
+	l.config.AllowedPrefix = prefixes
+	l.allowFileLogger = w
+	l.mu.Unlock()
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ErrorBurstConfig 在 Window 内出现 Threshold 条 ERROR 及以上的日志时，把最低等级临时降为 DEBUG，
+// Duration 之后恢复（与 BoostLevel 相同，期间 SetLevel 或 Reconfigure 会取消）。
+// 一次提升结束后至少间隔 Cooldown 才会再次触发，避免持续报错时反复切换。
+type ErrorBurstConfig struct {
+	Threshold int           // 触发所需的错误条数，默认 10
+	Window    time.Duration // 统计窗口，默认 10s
+	Duration  time.Duration // 保持 DEBUG 的时长，默认 1m
+	Cooldown  time.Duration // 提升结束后再次触发前的最短间隔，默认与 Duration 相同
+}
+
+// UnmarshalJSON 允许时长字段写成 "10s" 这样的字符串
+func (c *ErrorBurstConfig) UnmarshalJSON(data []byte) error {
+	type plain ErrorBurstConfig
+	aux := struct {
+		*plain
+		Window   *jsonDuration
+		Duration *jsonDuration
+		Cooldown *jsonDuration
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	for _, d := range []struct {
+		src *jsonDuration
+		dst *time.Duration
+	}{{aux.Window, &c.Window}, {aux.Duration, &c.Duration}, {aux.Cooldown, &c.Cooldown}} {
+		if d.src != nil {
+			*d.dst = time.Duration(*d.src)
+		}
+	}
+	return nil
+}
+
+// burstDetector 的状态只在 start() 协程中访问
+type burstDetector struct {
+	cfg        ErrorBurstConfig
+	times      []time.Time // 窗口内错误日志的时间，按先后排列
+	quietUntil time.Time   // 在此之前不再触发
+}
+
+func newBurstDetector(cfg *ErrorBurstConfig) *burstDetector {
+	if cfg == nil {
+		return nil
+	}
+	b := &burstDetector{cfg: *cfg}
+	if b.cfg.Threshold <= 0 {
+		b.cfg.Threshold = 10
+	}
+	if b.cfg.Window <= 0 {
+		b.cfg.Window = 10 * time.Second
+	}
+	if b.cfg.Duration <= 0 {
+		b.cfg.Duration = time.Minute
+	}
+	if b.cfg.Cooldown <= 0 {
+		b.cfg.Cooldown = b.cfg.Duration
+	}
+	return b
+}
+
+// observe 记录一条错误日志的时间，达到阈值且不在冷却期内时返回 true
+func (b *burstDetector) observe(t time.Time) bool {
+	cutoff := t.Add(-b.cfg.Window)
+	i := 0
+	for i < len(b.times) && !b.times[i].After(cutoff) {
+		i++
+	}
+	b.times = append(b.times[i:], t)
+	if len(b.times) < b.cfg.Threshold || t.Before(b.quietUntil) {
+		return false
+	}
+	b.times = b.times[:0]
+	b.quietUntil = t.Add(b.cfg.Duration + b.cfg.Cooldown)
+	return true
+}
+
+// autoDebug 在错误突增时切换到 DEBUG 并输出一条说明，只在 start() 中调用
+func (l *Logger) autoDebug() {
+	if l.GetLevel() <= DEBUG {
+		return
+	}
+	d := l.burst.cfg.Duration
+	l.BoostLevel(DEBUG, d)
+	l.pending.Add(1)
+	l.write(logMsg{
+		Level:   WARN,
+		Message: fmt.Sprintf("error burst detected, logging at DEBUG for %s", d),
+		Time:    time.Now(),
+		Caller:  "logger",
+	})
+}
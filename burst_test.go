@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试窗口内错误条数达到阈值后切换到 DEBUG、到期恢复，且冷却期内的新一轮突增不会再次提升
+func TestAutoDebugOnErrorBurst(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{
+		MinLevel: INFO,
+		AutoDebugOnErrorBurst: &ErrorBurstConfig{
+			Threshold: 3,
+			Window:    time.Second,
+			Duration:  50 * time.Millisecond,
+			Cooldown:  time.Hour,
+		},
+	})
+	defer log.Close()
+
+	log.Error("e1")
+	log.Error("e2")
+	log.Flush()
+	if got := log.GetLevel(); got != INFO {
+		t.Fatalf("level below threshold = %v; want INFO", got)
+	}
+	log.Error("e3")
+	log.Flush()
+	if got := log.GetLevel(); got != DEBUG {
+		t.Fatalf("level after burst = %v; want DEBUG", got)
+	}
+	if !strings.Contains(buf.String(), "error burst detected") {
+		t.Errorf("output missing the elevation notice:\n%s", buf.String())
+	}
+	waitLevel(t, log, INFO)
+
+	for i := 0; i < 5; i++ {
+		log.Error("again")
+	}
+	log.Flush()
+	if got := log.GetLevel(); got != INFO {
+		t.Errorf("level during cooldown = %v; want INFO", got)
+	}
+	if n := strings.Count(buf.String(), "error burst detected"); n != 1 {
+		t.Errorf("elevated %d times; want 1", n)
+	}
+}
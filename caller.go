@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// callerInfo 是调用位置的结构化信息
+type callerInfo struct {
+	File string // 文件名（不含目录）
+	Path string // 编译时记录的完整路径
+	Line int
+	Func string // 函数名（去掉包路径前缀，保留包名）
+
+	FullFunc string // 完整函数名，含导入路径
+
+	str string // 缓存的 String() 结果，由 getCallerInfo 填入
+}
+
+func (c callerInfo) String() string {
+	if c.str != "" {
+		return c.str
+	}
+	return fmt.Sprintf("%s:%d %s", c.File, c.Line, c.Func)
+}
+
+// callerCacheSize 是 callerCache 最多缓存的调用位置数，超过后新的调用位置不再缓存
+const callerCacheSize = 4096
+
+// callerCache 以 pc 为键缓存解析好的调用位置，同一位置重复记录日志时跳过函数名查找与字符串拼接
+var (
+	callerCache      sync.Map // uintptr -> callerInfo
+	callerCacheCount atomic.Int64
+)
+
+// getCallerInfo 返回调用栈上第 skip 层的调用位置，skip 为 0 表示 getCallerInfo 的直接调用者
+func getCallerInfo(skip int) (callerInfo, bool) {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return callerInfo{}, false
+	}
+	// 内联时不同的逻辑调用位置可能共用 pc，文件与行号一致才使用缓存
+	if v, hit := callerCache.Load(pc); hit {
+		if c := v.(callerInfo); c.Line == line && c.Path == file {
+			return c, true
+		}
+		return newCallerInfo(pc, file, line), true
+	}
+	c := newCallerInfo(pc, file, line)
+	if callerCacheCount.Load() < callerCacheSize {
+		if _, loaded := callerCache.LoadOrStore(pc, c); !loaded {
+			callerCacheCount.Add(1)
+		}
+	}
+	return c, true
+}
+
+func newCallerInfo(pc uintptr, file string, line int) callerInfo {
+	fn := runtime.FuncForPC(pc).Name()
+	// 只取最后一个 / 之后的部分，不用 strings.Split 以免在热路径上分配
+	shortFunc := fn[strings.LastIndexByte(fn, '/')+1:]
+	shortFile := file[strings.LastIndexByte(file, '/')+1:]
+	c := callerInfo{File: shortFile, Path: file, Line: line, Func: shortFunc, FullFunc: fn}
+	c.str = c.String()
+	return c
+}
+
+// getCaller 返回 "file.go:42 pkg.Func" 形式的调用位置，skip 含义与 getCallerInfo 相同
+func getCaller(skip int) string {
+	c, ok := getCallerInfo(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	return c.String()
+}
+
+// setCaller 同时填充组合形式与拆分后的调用位置
+func (m *logMsg) setCaller(c callerInfo, ok bool) {
+	if !ok {
+		m.Caller = "unknown"
+		return
+	}
+	m.Caller = c.String()
+	m.File, m.Path, m.Line, m.Func = c.File, c.Path, c.Line, c.Func
+}
+
+// CallerPathStyle 决定 plain 格式中 caller 的文件部分如何显示
+type CallerPathStyle int
+
+const (
+	CallerShort    CallerPathStyle = iota // 只有文件名：main.go:42
+	CallerRelative                        // 相对 CallerRoot 的路径：cmd/app/main.go:42，不在其下的文件显示完整路径
+	CallerAbsolute                        // 完整路径：/home/me/app/cmd/app/main.go:42
+)
+
+func (s CallerPathStyle) MarshalText() ([]byte, error) {
+	switch s {
+	case CallerShort:
+		return []byte("short"), nil
+	case CallerRelative:
+		return []byte("relative"), nil
+	case CallerAbsolute:
+		return []byte("absolute"), nil
+	default:
+		return nil, fmt.Errorf("logger: invalid caller path style %d", int(s))
+	}
+}
+
+func (s *CallerPathStyle) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "short":
+		*s = CallerShort
+	case "relative":
+		*s = CallerRelative
+	case "absolute":
+		*s = CallerAbsolute
+	default:
+		return fmt.Errorf("logger: unknown caller path style %q", text)
+	}
+	return nil
+}
+
+// workDir 是 CallerRoot 为空时使用的项目根目录
+var workDir = sync.OnceValue(func() string {
+	dir, _ := os.Getwd()
+	return dir
+})
+
+// plainCaller 按 CallerPathStyle 渲染 plain 格式中的 caller，短路径时即为 msg.Caller
+func (l *Logger) plainCaller(msg logMsg) string {
+	style := l.config.CallerPathStyle
+	if style == CallerShort || msg.Path == "" {
+		return msg.Caller
+	}
+	p := msg.Path
+	if style == CallerRelative {
+		root := l.config.CallerRoot
+		if root == "" {
+			root = workDir()
+		}
+		// runtime 记录的路径总是使用 /
+		root = strings.TrimSuffix(filepath.ToSlash(root), "/")
+		if root != "" && strings.HasPrefix(p, root+"/") {
+			p = p[len(root)+1:]
+		}
+	}
+	return fmt.Sprintf("%s:%d %s", p, msg.Line, msg.Func)
+}
+
+// prefixLevels 是 Config.PrefixLevels 按前缀长度降序排好的副本
+type prefixLevels struct {
+	entries []prefixLevel
+	min     Level // 所有前缀中最低的等级，供 enabled 快速判断
+}
+
+type prefixLevel struct {
+	prefix string
+	level  Level
+}
+
+func newPrefixLevels(m map[string]Level) *prefixLevels {
+	if len(m) == 0 {
+		return nil
+	}
+	p := &prefixLevels{min: levelOff}
+	for prefix, level := range m {
+		p.entries = append(p.entries, prefixLevel{prefix: prefix, level: level})
+		if level < p.min {
+			p.min = level
+		}
+	}
+	sort.Slice(p.entries, func(i, j int) bool {
+		return len(p.entries[i].prefix) > len(p.entries[j].prefix)
+	})
+	return p
+}
+
+// level 返回与 fn 匹配的最长前缀对应的等级，没有匹配时返回 def
+func (p *prefixLevels) level(fn string, def Level) Level {
+	for _, e := range p.entries {
+		if strings.HasPrefix(fn, e.prefix) {
+			return e.level
+		}
+	}
+	return def
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func callSiteA() (callerInfo, bool) { return getCallerInfo(0) }
+func callSiteB() (callerInfo, bool) { return getCallerInfo(0) }
+
+// 测试缓存命中后各调用位置仍得到各自正确的文件、行号与函数名
+func TestCallerCacheDistinctSites(t *testing.T) {
+	for round := 0; round < 3; round++ {
+		for _, tc := range []struct {
+			site func() (callerInfo, bool)
+			fn   string
+		}{
+			{callSiteA, "logger.callSiteA"},
+			{callSiteB, "logger.callSiteB"},
+		} {
+			c, ok := tc.site()
+			_, file, line, _ := runtime.Caller(0)
+			if !ok || c.Func != tc.fn || c.File != "caller_test.go" || c.Path != file || c.Line >= line {
+				t.Errorf("round %d: got %+v; want %s in %s", round, c, tc.fn, file)
+			}
+			if want := "caller_test.go:" + strconv.Itoa(c.Line) + " " + tc.fn; c.String() != want {
+				t.Errorf("round %d: String() = %q; want %q", round, c.String(), want)
+			}
+		}
+	}
+
+	// 同一函数内的两个调用位置行号不同
+	first, _ := getCallerInfo(0)
+	second, _ := getCallerInfo(0)
+	if second.Line != first.Line+1 || !strings.HasSuffix(first.Func, "TestCallerCacheDistinctSites") {
+		t.Errorf("first %+v, second %+v", first, second)
+	}
+}
+
+// 对比同一热点调用位置在有无缓存时解析调用位置的开销
+func BenchmarkCallerInfoCached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c, _ := getCallerInfo(0)
+		_ = c.String()
+	}
+}
+
+func BenchmarkCallerInfoUncached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		pc, file, line, _ := runtime.Caller(0)
+		c := newCallerInfo(pc, file, line)
+		_ = c.String()
+	}
+}
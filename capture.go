@@ -0,0 +1,52 @@
+package logger
+
+import "sync"
+
+// Capture 收集 Logger 输出的每条记录，便于在测试中断言日志内容。
+// Lines 和 Messages 会先 Flush，因此调用后立即可见，无需 sleep。
+type Capture struct {
+	mu      sync.Mutex
+	log     *Logger
+	records []LogRecord
+}
+
+// NewCapturing 创建一个不输出到控制台或文件、只把记录收集到 Capture 中的 Logger，
+// 默认记录所有等级（可通过 SetLevel 调整）。
+func NewCapturing() (*Logger, *Capture) {
+	l := New(Config{MinLevel: TRACE, Targets: OutputNone})
+	c := &Capture{log: l}
+	l.capture = c
+	return l, c
+}
+
+func (c *Capture) add(msg logMsg) {
+	c.mu.Lock()
+	c.records = append(c.records, msg.record())
+	c.mu.Unlock()
+}
+
+// Lines 返回目前为止收到的全部记录
+func (c *Capture) Lines() []LogRecord {
+	c.log.Flush()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]LogRecord(nil), c.records...)
+}
+
+// Messages 返回目前为止收到的全部日志消息文本
+func (c *Capture) Messages() []string {
+	lines := c.Lines()
+	msgs := make([]string, len(lines))
+	for i, m := range lines {
+		msgs[i] = m.Message
+	}
+	return msgs
+}
+
+// Reset 清空已收集的记录
+func (c *Capture) Reset() {
+	c.log.Flush()
+	c.mu.Lock()
+	c.records = nil
+	c.mu.Unlock()
+}
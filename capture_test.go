@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 测试 Capture 无需 sleep 即可断言刚写入的记录
+func TestCapture(t *testing.T) {
+	log, capture := NewCapturing()
+	defer log.Close()
+
+	log.Debug("first")
+	log.Named("db").Errorw("second", "code", 7)
+
+	if got, want := capture.Messages(), []string{"first", "second"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Messages() = %v; want %v", got, want)
+	}
+
+	lines := capture.Lines()
+	if lines[1].Level != ERROR || lines[1].Component != "db" {
+		t.Errorf("second record = %+v", lines[1])
+	}
+	if len(lines[1].Fields) != 1 || lines[1].Fields[0] != (Field{Key: "code", Value: 7}) {
+		t.Errorf("second record fields = %v", lines[1].Fields)
+	}
+
+	capture.Reset()
+	log.SetLevel(WARN)
+	log.Info("filtered")
+	log.Warn("kept")
+	if got := capture.Messages(); !reflect.DeepEqual(got, []string{"kept"}) {
+		t.Errorf("Messages() after Reset = %v; want [kept]", got)
+	}
+}
+
+// 测试 Flush 在关闭后调用也不会阻塞
+func TestFlushAfterClose(t *testing.T) {
+	log, capture := NewCapturing()
+	log.Info("before close")
+	log.Close()
+	log.Flush()
+	if got := capture.Messages(); len(got) != 1 {
+		t.Errorf("Messages() = %v; want one record", got)
+	}
+}
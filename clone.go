@@ -0,0 +1,42 @@
+package logger
+
+// cloneConfig 是 Clone 派生的 Logger 独立持有的配置，由克隆及其 With 等派生的 Logger 共享
+type cloneConfig struct {
+	levels  levelState
+	formats formatSet
+}
+
+// Clone 返回一个派生 Logger，modify 可修改当前配置的副本。派生 Logger 与原 Logger 共享通道、
+// 后台写出协程与全部写入器，不会新开文件；只有 MinLevel、Format、ConsoleFormat、FileFormat 与 Targets
+// 对它输出的日志生效，其余字段（路径、轮转、脱敏、白名单等）仍以共享的配置为准，修改会被忽略。
+// 这些设置在创建时固定，不跟随原 Logger 的 SetLevel 与 Reconfigure。克隆有自己的等级：对它调用 SetLevel、
+// BoostLevel、PushLevel 只修改克隆的等级，GetLevel 返回它；Reconfigure、Close 等其余方法作用于共享的 core，
+// 与在原 Logger 上调用相同。modify 为 nil 时按原样复制当前配置。
+func (l *Logger) Clone(modify func(*Config)) *Logger {
+	if l.nop {
+		return l
+	}
+	cfg := l.Config()
+	if modify != nil {
+		modify(&cfg)
+	}
+	child := *l
+	child.clone = &cloneConfig{formats: cfg.formats()}
+	child.clone.levels.set(cfg.MinLevel)
+	targets := cfg.Targets
+	child.targets = &targets
+	return &child
+}
+
+// levels 返回这个 Logger 的等级状态：克隆使用自己的，否则为 core 中的
+func (l *Logger) levels() *levelState {
+	if l.clone != nil {
+		return &l.clone.levels
+	}
+	return &l.core.levelState
+}
+
+// levelFloor 返回这个 Logger 的最低输出等级
+func (l *Logger) levelFloor() Level {
+	return Level(l.levels().minLevel.Load())
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试克隆使用自己的等级与格式，与原 Logger 写入同一个文件写入器
+func TestClone(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{MinLevel: INFO})
+	debug := log.Clone(func(c *Config) {
+		c.MinLevel = DEBUG
+		c.Format = FormatJSON
+	})
+	log.Debug("parent debug")
+	debug.Debug("clone debug")
+	log.Info("parent info")
+	log.SetLevel(ERROR)
+	debug.Info("clone info")
+	log.Close()
+
+	out := buf.String()
+	if strings.Contains(out, "parent debug") {
+		t.Errorf("parent logged below its level: %q", out)
+	}
+	for _, want := range []string{`"level":"DEBUG"`, `"message":"clone debug"`, "[INFO]", "parent info", `"message":"clone info"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q: %q", want, out)
+		}
+	}
+	if log.fileLogger != debug.fileLogger {
+		t.Error("clone has its own file writer")
+	}
+	if got := debug.GetLevel(); got != DEBUG {
+		t.Errorf("clone level = %v; want DEBUG", got)
+	}
+	if got := debug.Config().Format; got != FormatJSON {
+		t.Errorf("clone Config().Format = %v; want JSON", got)
+	}
+}
+
+// 测试对克隆（及其 With 派生的 Logger）调用 SetLevel、PushLevel 只改变克隆的等级，原 Logger 不受影响
+func TestCloneSetLevel(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{MinLevel: INFO})
+	clone := log.Clone(nil)
+	child := clone.With("k", "v")
+
+	child.SetLevel(DEBUG)
+	if got := log.GetLevel(); got != INFO {
+		t.Errorf("parent level = %v; want INFO", got)
+	}
+	if got, gotChild := clone.GetLevel(), child.GetLevel(); got != DEBUG || gotChild != DEBUG {
+		t.Errorf("clone level = %v, child level = %v; want DEBUG", got, gotChild)
+	}
+	restore := clone.PushLevel(ERROR)
+	if got := clone.GetLevel(); got != ERROR || log.GetLevel() != INFO {
+		t.Errorf("after PushLevel clone = %v, parent = %v; want ERROR, INFO", got, log.GetLevel())
+	}
+	restore()
+
+	log.Debug("parent debug")
+	clone.Debug("clone debug")
+	log.SetLevel(WARN)
+	if got := clone.GetLevel(); got != DEBUG {
+		t.Errorf("clone level after parent SetLevel = %v; want DEBUG", got)
+	}
+	log.Close()
+
+	out := buf.String()
+	if strings.Contains(out, "parent debug") || !strings.Contains(out, "clone debug") {
+		t.Errorf("output = %q; want only the clone's debug line", out)
+	}
+}
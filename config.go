@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LoadConfig 从 JSON 文件读取配置。文件中未出现的字段使用 DefaultConfig 的值，
+// 等级、格式、输出目标等使用名称表示，时长字段接受 "10s" 这样的字符串：
+//
+//	{"MinLevel": "debug", "Format": "json", "Targets": ["console", "file"], "LogPath": "logs/app.log"}
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("logger: read config: %w", err)
+	}
+	return parseConfig(data)
+}
+
+// configFile 用同名字段覆盖 Config 中的时长字段，使其可以写成字符串
+type configFile struct {
+	*Config
+	DropReportInterval    *jsonDuration
+	FlushInterval         *jsonDuration
+	HeartbeatInterval     *jsonDuration
+	EnqueueTimeout        *jsonDuration
+	AlertCooldown         *jsonDuration
+	DeadlineWarnThreshold *jsonDuration
+}
+
+func parseConfig(data []byte) (Config, error) {
+	cfg := DefaultConfig()
+	file := configFile{Config: &cfg}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return Config{}, fmt.Errorf("logger: parse config: %w", err)
+	}
+	if file.DropReportInterval != nil {
+		cfg.DropReportInterval = time.Duration(*file.DropReportInterval)
+	}
+	if file.FlushInterval != nil {
+		cfg.FlushInterval = time.Duration(*file.FlushInterval)
+	}
+	if file.HeartbeatInterval != nil {
+		cfg.HeartbeatInterval = time.Duration(*file.HeartbeatInterval)
+	}
+	if file.EnqueueTimeout != nil {
+		cfg.EnqueueTimeout = time.Duration(*file.EnqueueTimeout)
+	}
+	if file.AlertCooldown != nil {
+		cfg.AlertCooldown = time.Duration(*file.AlertCooldown)
+	}
+	if file.DeadlineWarnThreshold != nil {
+		cfg.DeadlineWarnThreshold = time.Duration(*file.DeadlineWarnThreshold)
+	}
+	if err := validatePaths(cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// validatePaths 检查日志路径不是目录，且其父路径不是普通文件
+func validatePaths(cfg Config) error {
+	if cfg.Targets&OutputFile == 0 || cfg.LogPath == "" {
+		return nil
+	}
+	if info, err := os.Stat(cfg.LogPath); err == nil && info.IsDir() {
+		return fmt.Errorf("logger: LogPath %q is a directory", cfg.LogPath)
+	}
+	if info, err := os.Stat(filepath.Dir(cfg.LogPath)); err == nil && !info.IsDir() {
+		return fmt.Errorf("logger: parent of LogPath %q is not a directory", cfg.LogPath)
+	}
+	return nil
+}
+
+// jsonDuration 接受 "1.5s" 这样的字符串或纳秒整数
+type jsonDuration time.Duration
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = jsonDuration(parsed)
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("duration must be a string like \"10s\" or nanoseconds: %s", data)
+	}
+	*d = jsonDuration(n)
+	return nil
+}
+
+// watchInterval 是 WatchConfig 轮询配置文件的间隔
+var watchInterval = time.Second
+
+// WatchConfig 先加载 path 并应用，然后轮询该文件，内容变化时重新加载并调用 Reconfigure。
+// 解析或应用失败的修改会被忽略（保留上一次的有效配置）并输出一条 WARN；Hooks、Formatter、ConsoleWriter 等
+// 只能在代码中设置的字段在每次加载时沿用当前值。
+// 返回的 stop 用于停止监听，可重复调用；Logger 关闭后监听也会自动结束。
+func (l *Logger) WatchConfig(path string) (stop func(), err error) {
+	if l.nop {
+		return func() {}, nil
+	}
+	last, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("logger: read config: %w", err)
+	}
+	cfg, err := parseConfig(last)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.Reconfigure(keepCodeOnlyFields(cfg, l.Config())); err != nil {
+		return nil, err
+	}
+
+	quit := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-l.done:
+				return
+			case <-ticker.C:
+			}
+			data, err := os.ReadFile(path)
+			if err != nil || bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+			cfg, err := parseConfig(data)
+			if err != nil {
+				l.logInternal(WARN, "logger: ignoring invalid config change, keeping last good config",
+					Field{Key: "path", Value: path}, Field{Key: "error", Value: err.Error()})
+				continue
+			}
+			if err := l.Reconfigure(keepCodeOnlyFields(cfg, l.Config())); err != nil {
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				l.logInternal(WARN, "logger: ignoring config change that could not be applied, keeping last good config",
+					Field{Key: "path", Value: path}, Field{Key: "error", Value: err.Error()})
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(quit) }) }, nil
+}
+
+// keepCodeOnlyFields 把配置文件无法表达、只能在代码中设置的字段从 cur 复制到 cfg，
+// 避免重新加载配置文件时丢掉钩子、写入器与回调
+func keepCodeOnlyFields(cfg, cur Config) Config {
+	cfg.Formatter = cur.Formatter
+	cfg.ConsoleWriter = cur.ConsoleWriter
+	cfg.OnRotate = cur.OnRotate
+	cfg.OnErrorAlert = cur.OnErrorAlert
+	cfg.AlertSignature = cur.AlertSignature
+	cfg.Hooks = cur.Hooks
+	cfg.FallbackWriter = cur.FallbackWriter
+	cfg.SeverityMap = cur.SeverityMap
+	cfg.CorrelationIDFunc = cur.CorrelationIDFunc
+	return cfg
+}
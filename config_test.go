@@ -0,0 +1,270 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "logger.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+// 测试完整配置文件的解析
+func TestLoadConfigComplete(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	path := writeConfigFile(t, `{
+		"MinLevel": "debug",
+		"Format": "json",
+		"Targets": ["console", "file"],
+		"LogPath": "`+filepath.ToSlash(logPath)+`",
+		"AllowedPrefix": ["payments"],
+		"LevelColors": {"INFO": "\u001b[34m"},
+		"JSONKeys": {"Time": "@timestamp"},
+		"Overflow": "drop",
+		"DropReportInterval": "30s",
+		"FlushInterval": "1s",
+		"HeartbeatInterval": "1m",
+		"FileRotation": {"MaxSize": 50, "Compress": false}
+	}`)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	compress := false
+	want := Config{
+		MinLevel:           DEBUG,
+		Format:             FormatJSON,
+		Targets:            OutputConsole | OutputFile,
+		LogPath:            filepath.ToSlash(logPath),
+		AllowedPrefix:      []string{"payments"},
+		LevelColors:        map[Level]string{INFO: "\033[34m"},
+		JSONKeys:           JSONKeys{Time: "@timestamp"},
+		Overflow:           OverflowDrop,
+		DropReportInterval: 30 * time.Second,
+		FlushInterval:      time.Second,
+		HeartbeatInterval:  time.Minute,
+		FileRotation:       RotateConfig{MaxSize: 50, Compress: &compress},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("LoadConfig =\n%+v\nwant\n%+v", cfg, want)
+	}
+}
+
+// 测试后来加入的时长字段同样接受字符串形式
+func TestLoadConfigDurations(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		get     func(Config) time.Duration
+		want    time.Duration
+	}{
+		{`{"EnqueueTimeout": "5s"}`, func(c Config) time.Duration { return c.EnqueueTimeout }, 5 * time.Second},
+		{`{"AlertCooldown": "1m"}`, func(c Config) time.Duration { return c.AlertCooldown }, time.Minute},
+		{`{"DeadlineWarnThreshold": "100ms"}`, func(c Config) time.Duration { return c.DeadlineWarnThreshold }, 100 * time.Millisecond},
+	} {
+		cfg, err := LoadConfig(writeConfigFile(t, tc.content))
+		if err != nil {
+			t.Errorf("LoadConfig(%s): %v", tc.content, err)
+			continue
+		}
+		if got := tc.get(cfg); got != tc.want {
+			t.Errorf("LoadConfig(%s) = %v; want %v", tc.content, got, tc.want)
+		}
+	}
+}
+
+// 测试部分配置文件时其余字段使用默认值
+func TestLoadConfigPartial(t *testing.T) {
+	cfg, err := LoadConfig(writeConfigFile(t, `{"MinLevel": "warn"}`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	want := DefaultConfig()
+	want.MinLevel = WARN
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("LoadConfig = %+v; want %+v", cfg, want)
+	}
+}
+
+// 测试格式错误、未知字段、未知名称与非法路径都会返回错误
+func TestLoadConfigInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"malformed":      `{"MinLevel": "debug"`,
+		"unknown field":  `{"MinLevle": "debug"}`,
+		"unknown level":  `{"MinLevel": "verbose"}`,
+		"unknown target": `{"Targets": ["syslog"]}`,
+		"bad duration":   `{"DropReportInterval": "soon"}`,
+		"dir as path":    `{"Targets": ["file"], "LogPath": "` + filepath.ToSlash(dir) + `"}`,
+	}
+	for name, content := range cases {
+		if _, err := LoadConfig(writeConfigFile(t, content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		} else if !strings.HasPrefix(err.Error(), "logger:") {
+			t.Errorf("%s: error %q should be prefixed with logger:", name, err)
+		}
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+// 测试修改配置文件后新等级生效，非法修改被忽略并输出 WARN
+func TestWatchConfig(t *testing.T) {
+	old := watchInterval
+	watchInterval = 10 * time.Millisecond
+	defer func() { watchInterval = old }()
+
+	path := writeConfigFile(t, `{"MinLevel": "info", "Targets": []}`)
+	log, capture := NewCapturing()
+	defer log.Close()
+
+	stop, err := log.WatchConfig(path)
+	if err != nil {
+		t.Fatalf("WatchConfig: %v", err)
+	}
+	defer stop()
+	if got := log.GetLevel(); got != INFO {
+		t.Fatalf("initial level = %v; want INFO", got)
+	}
+
+	waitLevel := func(want Level) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for log.GetLevel() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("level = %v; want %v", log.GetLevel(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(`{"MinLevel": "debug", "Targets": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitLevel(DEBUG)
+	log.Debug("debug visible")
+
+	if err := os.WriteFile(path, []byte(`{"MinLevel": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !containsMessage(capture.Messages(), "ignoring invalid config") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no WARN for invalid config; messages = %v", capture.Messages())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := log.GetLevel(); got != DEBUG {
+		t.Errorf("level after invalid edit = %v; want DEBUG (last good)", got)
+	}
+	if !containsMessage(capture.Messages(), "debug visible") {
+		t.Errorf("debug message missing after level change")
+	}
+}
+
+// 测试 Reconfigure 拒绝的修改只输出 WARN 而不停止监听，且重新加载保留只能在代码中设置的字段
+func TestWatchConfigKeepsWatchingAndCodeOnlyFields(t *testing.T) {
+	old := watchInterval
+	watchInterval = 10 * time.Millisecond
+	defer func() { watchInterval = old }()
+
+	var hooked atomic.Int32
+	log, capture := NewCapturing()
+	defer log.Close()
+	if err := log.Reconfigure(Config{MinLevel: INFO, Targets: OutputNone, Hooks: []Hook{func(LogRecord) { hooked.Add(1) }}}); err != nil {
+		t.Fatal(err)
+	}
+	path := writeConfigFile(t, `{"MinLevel": "info", "Targets": []}`)
+	stop, err := log.WatchConfig(path)
+	if err != nil {
+		t.Fatalf("WatchConfig: %v", err)
+	}
+	defer stop()
+
+	// 祖父路径是普通文件：能通过解析时的检查，但 Reconfigure 创建目录会失败
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	bad := fmt.Sprintf(`{"MinLevel": "debug", "Targets": ["file"], "LogPath": %q}`, filepath.Join(blocker, "sub", "app.log"))
+	if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !containsMessage(capture.Messages(), "could not be applied") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no WARN for rejected config; messages = %v", capture.Messages())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := log.GetLevel(); got != INFO {
+		t.Errorf("level after rejected edit = %v; want INFO", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"MinLevel": "warn", "Targets": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for log.GetLevel() != WARN {
+		if time.Now().After(deadline) {
+			t.Fatalf("watcher stopped after a rejected edit; level = %v", log.GetLevel())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	before := hooked.Load()
+	log.Warn("after reload")
+	log.Flush()
+	if hooked.Load() != before+1 {
+		t.Error("Hooks were dropped by the reload")
+	}
+}
+
+func containsMessage(msgs []string, substr string) bool {
+	for _, m := range msgs {
+		if strings.Contains(m, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// 测试日志目录无法创建时 NewWithError 返回错误
+func TestNewWithErrorMkdirFailure(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	log, err := NewWithError(Config{
+		Targets: OutputFile,
+		LogPath: filepath.Join(blocker, "sub", "app.log"),
+	})
+	if err == nil {
+		log.Close()
+		t.Fatalf("expected error when the log dir cannot be created")
+	}
+	if log != nil {
+		t.Errorf("expected nil Logger on error")
+	}
+	if !strings.Contains(err.Error(), "create log dir") {
+		t.Errorf("error = %v; want create log dir failure", err)
+	}
+
+	// 正常路径不返回错误
+	log, err = NewWithError(Config{Targets: OutputFile, LogPath: filepath.Join(t.TempDir(), "ok.log")})
+	if err != nil {
+		t.Fatalf("NewWithError: %v", err)
+	}
+	log.Close()
+}
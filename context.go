@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// TraceCtx 等方法与 Tracew 等相同，但通道已满而阻塞时，ctx 结束即放弃这条日志并计入丢弃数，
+// 避免已取消请求的处理协程卡在日志调用上。OverflowDrop 策略下与普通方法行为一致。
+// 配置了 DeadlineWarnThreshold 时，ctx 的截止时间已过或剩余不足该值的日志附加
+// ctx_deadline_remaining 字段（已过期为负数），DeadlineBumpToWarn 时低于 WARN 的等级提升为 WARN。
+func (l *Logger) TraceCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logwCtx(ctx, TRACE, msg, keysAndValues)
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logwCtx(ctx, DEBUG, msg, keysAndValues)
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logwCtx(ctx, INFO, msg, keysAndValues)
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logwCtx(ctx, WARN, msg, keysAndValues)
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logwCtx(ctx, ERROR, msg, keysAndValues)
+}
+
+func (l *Logger) logwCtx(ctx context.Context, level Level, msg string, keysAndValues []interface{}) {
+	if l.nop {
+		return
+	}
+	remaining, near := l.deadlineRemaining(ctx)
+	if near && level < WARN && l.deadlineBump.Load() {
+		level = WARN
+	}
+	if !l.enabled(level) {
+		return
+	}
+	fields := sweetenFields(keysAndValues)
+	if near {
+		fields = append(fields, Field{Key: DeadlineRemainingField, Value: remaining})
+	}
+	l.logCtx(ctx, 2, level, msg, fields)
+}
+
+// DeadlineRemainingField 是 DeadlineWarnThreshold 附加的字段名，值为 time.Duration，JSON 中输出为 ctx_deadline_remaining_ms
+const DeadlineRemainingField = "ctx_deadline_remaining"
+
+// deadlineRemaining 返回 ctx 距截止时间的剩余时长，以及它是否已过期或不足 DeadlineWarnThreshold
+func (l *Logger) deadlineRemaining(ctx context.Context) (time.Duration, bool) {
+	threshold := time.Duration(l.deadlineThreshold.Load())
+	if threshold <= 0 {
+		return 0, false
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(deadline)
+	return remaining, remaining < threshold
+}
+
+type ctxKey struct{}
+
+// NewContext 返回携带 l 的派生 ctx，之后可用 FromContext 取出
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext 返回 ctx 中由 NewContext 或 Middleware 放入的 Logger，没有时返回单例 GetLoggerInstance()
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		return l
+	}
+	return GetLoggerInstance()
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试通道已满且消费协程停顿时，Ctx 方法在 ctx 取消后立即返回并计为丢弃
+func TestCtxAbandonsBlockedSend(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: DEBUG})
+	gate := newGateWriter()
+	log.fileLogger = gate
+
+	// 第一条卡在写入器中，之后的填满通道
+	for i := 0; i <= cap(log.logChan); i++ {
+		log.Info("fill " + strconv.Itoa(i))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	returned := make(chan struct{})
+	go func() {
+		log.InfoCtx(ctx, "abandoned", "k", 1)
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("InfoCtx blocked despite a cancelled context")
+	}
+	if got := log.Stats().Dropped; got != 1 {
+		t.Errorf("Dropped = %d; want 1", got)
+	}
+
+	close(gate.release)
+	log.InfoCtx(context.Background(), "delivered", "k", 2)
+	log.Close()
+	out := gate.String()
+	if strings.Contains(out, "abandoned") || !strings.Contains(out, "delivered k=2") {
+		t.Errorf("unexpected output tail: %q", out[max(0, len(out)-200):])
+	}
+}
+
+// 测试 DeadlineWarnThreshold：没有截止时间或剩余充足时不附加字段，临近或已过期时附加剩余时长，
+// DeadlineBumpToWarn 时 INFO 提升为 WARN、ERROR 保持不变，原本被过滤的 DEBUG 也会输出
+func TestCtxDeadlineWarning(t *testing.T) {
+	far, cancelFar := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelFar()
+	near, cancelNear := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancelNear()
+	past, cancelPast := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelPast()
+
+	for _, bump := range []bool{false, true} {
+		log, capture := NewCapturing()
+		if err := log.Reconfigure(Config{MinLevel: INFO, Targets: OutputNone, DeadlineWarnThreshold: time.Second, DeadlineBumpToWarn: bump}); err != nil {
+			t.Fatal(err)
+		}
+		log.InfoCtx(context.Background(), "no deadline")
+		log.InfoCtx(far, "far")
+		log.InfoCtx(near, "near")
+		log.InfoCtx(past, "past")
+		log.ErrorCtx(near, "near error")
+		log.DebugCtx(near, "near debug")
+		log.Close()
+
+		got := map[string]LogRecord{}
+		for _, r := range capture.Lines() {
+			got[r.Message] = r
+		}
+		for _, msg := range []string{"no deadline", "far"} {
+			if r := got[msg]; r.Level != INFO || fieldValue(r.Fields, DeadlineRemainingField) != nil {
+				t.Errorf("bump=%v %s: level %v fields %v; want INFO without %s", bump, msg, r.Level, r.Fields, DeadlineRemainingField)
+			}
+		}
+		wantLevel := INFO
+		if bump {
+			wantLevel = WARN
+		}
+		for msg, check := range map[string]func(time.Duration) bool{
+			"near": func(d time.Duration) bool { return d > 0 && d <= 500*time.Millisecond },
+			"past": func(d time.Duration) bool { return d < 0 },
+		} {
+			r := got[msg]
+			d, _ := fieldValue(r.Fields, DeadlineRemainingField).(time.Duration)
+			if r.Level != wantLevel || !check(d) {
+				t.Errorf("bump=%v %s: level %v remaining %v", bump, msg, r.Level, d)
+			}
+		}
+		if r := got["near error"]; r.Level != ERROR {
+			t.Errorf("bump=%v near error: level %v; want ERROR", bump, r.Level)
+		}
+		if _, ok := got["near debug"]; ok != bump {
+			t.Errorf("bump=%v near debug logged = %v", bump, ok)
+		}
+	}
+}
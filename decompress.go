@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupPaths 返回 DecompressExistingBackups 需要处理的日志文件路径，其备份与之位于同一目录
+func backupPaths(cfg Config) []string {
+	var paths []string
+	if cfg.Targets&OutputFile != 0 {
+		paths = append(paths, cfg.LogPath)
+	}
+	if len(cfg.AllowedPrefix) > 0 {
+		paths = append(paths, allowedLogPath)
+	}
+	return paths
+}
+
+// decompressBackups 把 paths 各自目录下 lumberjack 压缩的备份（name-时间戳.ext.gz，
+// RotateDaily 时为当天文件 name-日期.ext 的备份 name-日期-时间戳.ext.gz）解压回原文件名并删除 .gz，在独立协程中运行。解压失败（如写了一半的 gz 文件）时保留 .gz 并输出一条 WARN。
+func (l *Logger) decompressBackups(paths []string) {
+	for _, path := range paths {
+		dir, base := filepath.Split(path)
+		ext := filepath.Ext(base)
+		prefix, suffix := strings.TrimSuffix(base, ext)+"-", ext+".gz"
+		entries, _ := os.ReadDir(filepath.Clean(dir))
+		for _, e := range entries {
+			name := e.Name()
+			if !e.Type().IsRegular() || !isBackupName(name, datedPrefix(name, prefix), suffix) {
+				continue
+			}
+			gz := filepath.Join(dir, name)
+			if err := decompressFile(gz); err != nil {
+				l.enqueue(logMsg{
+					Level:   WARN,
+					Message: fmt.Sprintf("decompress backup %s: %v", gz, err),
+					Time:    time.Now(),
+					Caller:  "logger",
+				})
+			}
+		}
+	}
+}
+
+// backupTimeFormat 与 lumberjack 备份文件名中的时间戳格式一致
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// datedPrefix 在 name 以 prefix+日期- 开头时返回包含日期的前缀，即按日期切换的文件 name-日期.ext 的备份前缀，否则返回 prefix
+func datedPrefix(name, prefix string) string {
+	const date = "2006-01-02"
+	end := len(prefix) + len(date)
+	if len(name) <= end || !strings.HasPrefix(name, prefix) || name[end] != '-' {
+		return prefix
+	}
+	if _, err := time.Parse(date, name[len(prefix):end]); err != nil {
+		return prefix
+	}
+	return name[:end+1]
+}
+
+// isBackupName 判断 name 是否为 prefix+时间戳+suffix 形式的 lumberjack 备份，其余 .gz 文件一律不动
+func isBackupName(name, prefix, suffix string) bool {
+	_, ok := backupStamp(name, prefix, suffix)
+	return ok
+}
+
+// backupStamp 返回 lumberjack 备份名中的时间戳，name 不是 prefix+时间戳+suffix 形式时 ok 为 false
+func backupStamp(name, prefix, suffix string) (stamp string, ok bool) {
+	if len(name) < len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+	stamp = name[len(prefix) : len(name)-len(suffix)]
+	if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+		return "", false
+	}
+	return stamp, true
+}
+
+// decompressFile 先解压到临时文件，完整读出后才改名并删除 .gz；目标文件已存在时不覆盖
+func decompressFile(gz string) (err error) {
+	dst := strings.TrimSuffix(gz, ".gz")
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	in, err := os.Open(gz)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+	if _, err = io.Copy(out, zr); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	_ = in.Close()
+	return os.Remove(gz)
+}
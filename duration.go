@@ -0,0 +1,29 @@
+package logger
+
+import "time"
+
+// WithDuration 返回附加了耗时字段的派生 Logger，等同于 With(key, d)。
+// time.Duration 类型的字段在 JSON 中输出为 key_ms 数值（毫秒，保留小数），plain 与 logfmt 中输出 1.5s、250µs 等可读形式。
+func (l *Logger) WithDuration(key string, d time.Duration) *Logger {
+	return l.With(key, d)
+}
+
+// durationMillis 返回 d 的毫秒数，精确到纳秒，不足 1ms 时为小数
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// humanDuration 按量级舍去多余精度：1s 以上精确到毫秒，1ms 以上精确到微秒，更短的原样输出
+func humanDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= time.Second:
+		d = d.Round(time.Millisecond)
+	case abs >= time.Millisecond:
+		d = d.Round(time.Microsecond)
+	}
+	return d.String()
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"slices"
+	"strings"
+)
+
+// ecsVersion 是 FormatECS 输出遵循的 Elastic Common Schema 版本
+const ecsVersion = "8.11.0"
+
+// formatECS 按 Elastic Common Schema 输出：@timestamp、log（level、logger、origin）、message、ecs.version，
+// Event 的事件代码与 IncludeSequence 的序号分别为 event.action 与 event.sequence，之后是结构化字段。caller 拆分为 log.origin.file.name、log.origin.file.line
+// 与 log.origin.function，Named 设置的名称为 log.logger。JSONKeys、SplitCaller 与 TimePrecision 不影响此格式。
+func (l *Logger) formatECS(msg logMsg) string {
+	var obj jsonObject
+	obj.add("@timestamp", msg.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+
+	logObj := fieldGroup{{Key: "level", Value: strings.ToLower(levelToStr(msg.Level))}}
+	if msg.Component != "" {
+		logObj = append(logObj, Field{Key: "logger", Value: msg.Component})
+	}
+	if msg.File != "" {
+		logObj = append(logObj, Field{Key: "origin", Value: fieldGroup{
+			{Key: "file", Value: fieldGroup{{Key: "name", Value: msg.File}, {Key: "line", Value: msg.Line}}},
+			{Key: "function", Value: msg.Func},
+		}})
+	}
+	obj.add("log", logObj)
+	obj.add("message", msg.Message)
+	obj.add("ecs", fieldGroup{{Key: "version", Value: ecsVersion}})
+	fields := mergeGroups(msg.Fields)
+	var event fieldGroup
+	if i := slices.IndexFunc(fields, func(f Field) bool { return f.Key == EventField }); i >= 0 {
+		// Event 设置的事件代码对应 ECS 的 event.action
+		event = append(event, Field{Key: "action", Value: fields[i].Value})
+		fields = slices.Delete(slices.Clone(fields), i, i+1)
+	}
+	if msg.Seq != 0 {
+		event = append(event, Field{Key: "sequence", Value: msg.Seq})
+	}
+	if len(event) > 0 {
+		obj.add("event", event)
+	}
+	for _, f := range fields {
+		obj.add(f.Key, f.Value)
+	}
+	return obj.String() + l.lineSeparator()
+}
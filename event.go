@@ -0,0 +1,63 @@
+package logger
+
+import "strings"
+
+// EventField 是 Event 等方法设置的事件代码字段名；EventRawField 在代码被修正时保留原始值
+const (
+	EventField    = "event"
+	EventRawField = "event_raw"
+)
+
+// maxEventCodeLen 是事件代码的最大长度，超出部分被截断
+const maxEventCodeLen = 128
+
+// Event 以 INFO 等级记录一条带事件代码的日志，code 写入独立的 event 字段（如 user.login.success），
+// 与面向人的 msg 分开，便于统计事件而无需匹配消息文本。code 只允许小写字母、数字、'.'、'_' 与 '-'：
+// 大写字母转为小写，其他字符替换为 '_'，修正过的代码另以 event_raw 字段保留原值；为空时记为 "invalid"。
+func (l *Logger) Event(code, msg string, keysAndValues ...interface{}) {
+	l.logEvent(INFO, code, msg, keysAndValues)
+}
+
+func (l *Logger) DebugEvent(code, msg string, keysAndValues ...interface{}) {
+	l.logEvent(DEBUG, code, msg, keysAndValues)
+}
+
+func (l *Logger) WarnEvent(code, msg string, keysAndValues ...interface{}) {
+	l.logEvent(WARN, code, msg, keysAndValues)
+}
+
+func (l *Logger) ErrorEvent(code, msg string, keysAndValues ...interface{}) {
+	l.logEvent(ERROR, code, msg, keysAndValues)
+}
+
+func (l *Logger) logEvent(level Level, code, msg string, keysAndValues []interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+	fields := []Field{{Key: EventField, Value: sanitizeEventCode(code)}}
+	if clean := fields[0].Value.(string); clean != code {
+		fields = append(fields, Field{Key: EventRawField, Value: code})
+	}
+	l.log(2, level, msg, append(fields, sweetenFields(keysAndValues)...))
+}
+
+// sanitizeEventCode 把 code 修正为只含小写字母、数字、'.'、'_' 与 '-' 的形式
+func sanitizeEventCode(code string) string {
+	if len(code) > maxEventCodeLen {
+		code = code[:maxEventCodeLen]
+	}
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, code)
+	if clean == "" {
+		return "invalid"
+	}
+	return clean
+}
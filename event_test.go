@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// 测试 Event 在 JSON 与 plain 中输出独立的 event 字段，非法代码被修正并保留原值
+func TestEvent(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, Format: FormatJSON})
+	log.Event("user.login.success", "用户登录", "user", "alice")
+	log.WarnEvent("User Login/Failed", "登录失败")
+	log.ErrorEvent("", "未知事件")
+	log.DebugEvent("hidden", "below level")
+	log.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines: %s", len(lines), buf.String())
+	}
+	want := []struct {
+		level, event, raw string
+	}{
+		{"INFO", "user.login.success", ""},
+		{"WARN", "user_login_failed", "User Login/Failed"},
+		{"ERROR", "invalid", ""},
+	}
+	for i, line := range lines {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		raw, _ := data[EventRawField].(string)
+		if data["level"] != want[i].level || data[EventField] != want[i].event || raw != want[i].raw {
+			t.Errorf("line %d = %s; want level %s event %q raw %q", i, line, want[i].level, want[i].event, want[i].raw)
+		}
+	}
+	if !strings.Contains(lines[0], `"message":"用户登录"`) || !strings.Contains(lines[0], `"user":"alice"`) {
+		t.Errorf("event line lost the message or fields: %s", lines[0])
+	}
+	if !strings.Contains(lines[0], "event_test.go") {
+		t.Errorf("caller should point at the test: %s", lines[0])
+	}
+
+	log, buf = newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO})
+	log.Event("order.created", "下单")
+	log.Close()
+	if out := buf.String(); !strings.Contains(out, "下单 event=order.created") {
+		t.Errorf("plain output = %q", out)
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrEventLogUnsupported 在非 Windows 平台启用 OutputEventLog 时返回
+var ErrEventLogUnsupported = errors.New("logger: Windows Event Log is only supported on windows")
+
+// eventLogID 是写入 Windows 事件日志时统一使用的事件 ID
+const eventLogID = 1
+
+// eventSink 是 Windows 事件日志句柄的最小接口，与 eventlog.Log 的方法一致，便于在测试中替换
+type eventSink interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+	Close() error
+}
+
+// eventLogSource 返回事件来源名称，未配置时使用可执行文件名（不含扩展名）
+func eventLogSource(cfg Config) string {
+	if cfg.EventLogSource != "" {
+		return cfg.EventLogSource
+	}
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// writeEvent 按等级映射事件类型：WARN 为 Warning，ERROR 为 Error，其余为 Information
+func writeEvent(sink eventSink, level Level, msg string) error {
+	msg = strings.TrimRight(msg, "\n")
+	switch {
+	case level >= ERROR:
+		return sink.Error(eventLogID, msg)
+	case level == WARN:
+		return sink.Warning(eventLogID, msg)
+	default:
+		return sink.Info(eventLogID, msg)
+	}
+}
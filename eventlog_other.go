@@ -0,0 +1,7 @@
+//go:build !windows
+
+package logger
+
+func openEventLog(source string) (eventSink, error) {
+	return nil, ErrEventLogUnsupported
+}
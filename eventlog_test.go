@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeEventLog 记录写入的事件类型与内容
+type fakeEventLog struct {
+	mu     sync.Mutex
+	events []string
+	closed bool
+}
+
+func (f *fakeEventLog) add(kind string, eid uint32, msg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, fmt.Sprintf("%s/%d/%s", kind, eid, msg))
+	return nil
+}
+
+func (f *fakeEventLog) Info(eid uint32, msg string) error    { return f.add("info", eid, msg) }
+func (f *fakeEventLog) Warning(eid uint32, msg string) error { return f.add("warning", eid, msg) }
+func (f *fakeEventLog) Error(eid uint32, msg string) error   { return f.add("error", eid, msg) }
+func (f *fakeEventLog) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+// 测试等级到事件类型的映射，以及 Close 关闭事件日志句柄
+func TestEventLogMapping(t *testing.T) {
+	fake := &fakeEventLog{}
+	log := New(Config{MinLevel: TRACE, Targets: OutputNone, IncludeCaller: new(bool)})
+	log.mu.Lock()
+	log.config.Targets = OutputEventLog
+	log.eventLog = fake
+	log.mu.Unlock()
+
+	log.Trace("t")
+	log.Debug("d")
+	log.Info("i")
+	log.Warn("w")
+	log.Error("e")
+	log.Close()
+
+	var kinds []string
+	for _, e := range fake.events {
+		kinds = append(kinds, strings.SplitN(e, "/", 2)[0])
+	}
+	want := "info,info,info,warning,error"
+	if got := strings.Join(kinds, ","); got != want {
+		t.Errorf("event types = %s; want %s", got, want)
+	}
+	if last := fake.events[len(fake.events)-1]; !strings.HasPrefix(last, "error/1/[ERROR]") || strings.HasSuffix(last, "\n") {
+		t.Errorf("last event = %q", last)
+	}
+	if !fake.closed {
+		t.Errorf("Close did not close the event log")
+	}
+}
+
+// 测试非 Windows 平台启用 OutputEventLog 时返回明确的错误
+func TestEventLogUnsupported(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("event log is supported on windows")
+	}
+	log, err := NewWithError(Config{Targets: OutputEventLog})
+	if log != nil || !errors.Is(err, ErrEventLogUnsupported) {
+		t.Errorf("NewWithError = %v, %v; want nil, ErrEventLogUnsupported", log, err)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package logger
+
+import "golang.org/x/sys/windows/svc/eventlog"
+
+// openEventLog 打开名为 source 的事件来源。来源未注册时事件仍会写入，
+// 但事件查看器会提示找不到描述，可用 eventlog.InstallAsEventCreate 预先注册。
+func openEventLog(source string) (eventSink, error) {
+	// 直接返回 eventlog.Open 的结果会把 nil 的 *eventlog.Log 包成非 nil 的 eventSink
+	lg, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return lg, nil
+}
@@ -1,8 +1,6 @@
 package main
 
 import (
-	"time"
-
 	"logger_test/test"
 
 	"github.com/xiangxu05/logger"
@@ -23,11 +21,8 @@ func main() {
 
 	// 捕获程序panic并记录
 	defer logger.RecoverAndLogPanic()
-	// 程序退出时关闭Logger，确保日志写入完成
-	defer func() {
-		time.Sleep(time.Millisecond * 100)
-		log.Close()
-	}()
+	// 程序退出时关闭Logger，Close 会等所有已入队的日志写完再返回
+	defer log.Close()
 
 	// 记录各种等级日志
 	log.Debug("调试信息")
@@ -0,0 +1,24 @@
+package logger
+
+import "io"
+
+// writeFallback 在通道已满时把日志按 Format 格式化后直接写入 FallbackWriter（不带颜色），
+// 未配置 FallbackWriter 时返回 false，由调用方按溢出策略处理。写入在调用方协程中进行，
+// 因此这些日志与经由通道写出的日志之间不保证顺序。
+func (l *Logger) writeFallback(msg logMsg) bool {
+	l.mu.RLock()
+	w := l.config.FallbackWriter
+	if w == nil {
+		l.mu.RUnlock()
+		return false
+	}
+	line := l.formatLogAs(l.formatsFor(msg).format, msg)
+	l.mu.RUnlock()
+
+	l.fallbackMu.Lock()
+	_, _ = io.WriteString(w, line)
+	l.fallbackMu.Unlock()
+	l.fallback.Add(1)
+	l.pending.Add(-1)
+	return true
+}
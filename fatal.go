@@ -0,0 +1,20 @@
+package logger
+
+import "os"
+
+// exit 在测试中替换，避免 Fatal 结束测试进程
+var exit = os.Exit
+
+// Fatal 以 FATAL 记录日志，关闭 Logger 写完所有已入队的日志后以退出码 1 结束进程
+func (l *Logger) Fatal(msg string) {
+	l.log(1, FATAL, msg, nil)
+	l.Close()
+	exit(1)
+}
+
+// FatalCode 与 Fatal 相同，但以 code 作为退出码，便于进程管理器区分不同的致命错误
+func (l *Logger) FatalCode(code int, msg string) {
+	l.log(1, FATAL, msg, nil)
+	l.Close()
+	exit(code)
+}
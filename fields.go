@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Field 是附加在单条日志上的结构化字段
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+const (
+	missingValue  = "!MISSING"     // 奇数个参数时悬空 key 的占位值
+	fieldErrorKey = "logger_error" // 记录键值对解析问题的字段名
+)
+
+// sweetenFields 将 Infow 等方法的可变键值对两两配对为字段。
+// 奇数个参数时最后一个 key 使用占位值，非 string 的 key 会被转换为字符串，
+// 两种情况都会额外附加 logger_error 字段说明问题。
+func sweetenFields(keysAndValues []interface{}) []Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+	var problems []string
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+			problems = append(problems, fmt.Sprintf("non-string key %q", key))
+		}
+		if i+1 >= len(keysAndValues) {
+			fields = append(fields, Field{Key: key, Value: missingValue})
+			problems = append(problems, fmt.Sprintf("odd number of keysAndValues, dangling key %q", key))
+			break
+		}
+		fields = append(fields, Field{Key: key, Value: keysAndValues[i+1]})
+	}
+	if len(problems) > 0 {
+		fields = append(fields, Field{Key: fieldErrorKey, Value: strings.Join(problems, "; ")})
+	}
+	return fields
+}
+
+// With 返回一个携带额外字段的派生 Logger，与原 Logger 共享通道和写入器。
+// 字段只解析一次，之后该 Logger 输出的每条日志都会带上它们；原 Logger 不受影响。
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	if l.nop || len(keysAndValues) == 0 {
+		return l
+	}
+	return l.withFields(sweetenFields(keysAndValues))
+}
+
+// withFields 返回追加了 fields（按当前分组嵌套）的派生 Logger
+func (l *Logger) withFields(fields []Field) *Logger {
+	child := *l
+	extra := nestFields(l.groups, fields)
+	child.fields = make([]Field, 0, len(l.fields)+len(extra))
+	child.fields = append(append(child.fields, l.fields...), extra...)
+	return &child
+}
+
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(TRACE) {
+		return
+	}
+	l.log(1, TRACE, msg, sweetenFields(keysAndValues))
+}
+
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(INFO) {
+		return
+	}
+	l.log(1, INFO, msg, sweetenFields(keysAndValues))
+}
+
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(ERROR) {
+		return
+	}
+	l.log(1, ERROR, msg, sweetenFields(keysAndValues))
+}
+
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(DEBUG) {
+		return
+	}
+	l.log(1, DEBUG, msg, sweetenFields(keysAndValues))
+}
+
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(WARN) {
+		return
+	}
+	l.log(1, WARN, msg, sweetenFields(keysAndValues))
+}
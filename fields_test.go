@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试偶数个键值对正常配对
+func TestSweetenFieldsEven(t *testing.T) {
+	got := sweetenFields([]interface{}{"user", 42, "ok", true})
+	want := []Field{{Key: "user", Value: 42}, {Key: "ok", Value: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sweetenFields = %v; want %v", got, want)
+	}
+}
+
+// 测试奇数个参数时悬空 key 得到占位值并记录错误
+func TestSweetenFieldsOdd(t *testing.T) {
+	got := sweetenFields([]interface{}{"user", 42, "dangling"})
+	if len(got) != 3 {
+		t.Fatalf("expected 3 fields, got %v", got)
+	}
+	if got[1] != (Field{Key: "dangling", Value: missingValue}) {
+		t.Errorf("dangling field = %v", got[1])
+	}
+	if got[2].Key != fieldErrorKey || !strings.Contains(got[2].Value.(string), "odd number") {
+		t.Errorf("error field = %v", got[2])
+	}
+}
+
+// 测试非 string 的 key 会被转换并记录错误
+func TestSweetenFieldsNonStringKey(t *testing.T) {
+	got := sweetenFields([]interface{}{7, "seven"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 fields, got %v", got)
+	}
+	if got[0] != (Field{Key: "7", Value: "seven"}) {
+		t.Errorf("converted field = %v", got[0])
+	}
+	if got[1].Key != fieldErrorKey || !strings.Contains(got[1].Value.(string), "non-string key") {
+		t.Errorf("error field = %v", got[1])
+	}
+}
+
+// 测试字段同时出现在纯文本与 JSON 输出中
+func TestFormatLogWithFields(t *testing.T) {
+	msg := logMsg{
+		Level:   INFO,
+		Message: "login",
+		Time:    time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		Caller:  "main.go:1 main.main",
+		Fields:  sweetenFields([]interface{}{"user", 42, "ok", true}),
+	}
+
+	plain := (&Logger{core: &core{config: Config{Format: FormatPlain}}}).formatLog(msg)
+	if !strings.HasSuffix(plain, "login user=42 ok=true\n") {
+		t.Errorf("plain output = %q", plain)
+	}
+
+	out := (&Logger{core: &core{config: Config{Format: FormatJSON}}}).formatLog(msg)
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if data["user"] != float64(42) || data["ok"] != true {
+		t.Errorf("JSON fields missing: %v", data)
+	}
+}
+
+// 测试 With 链式累积字段且不影响父 Logger
+func TestWithAccumulatesFields(t *testing.T) {
+	log, capture := NewCapturing()
+	defer log.Close()
+
+	reqLog := log.With("request_id", "r-1")
+	userLog := reqLog.With("user", 42)
+	otherLog := reqLog.With("user", 7)
+
+	userLog.Info("handled")
+	userLog.Errorw("failed", "code", 500)
+	otherLog.Info("other")
+	reqLog.Info("request only")
+	log.Info("parent")
+
+	want := [][]Field{
+		{{Key: "request_id", Value: "r-1"}, {Key: "user", Value: 42}},
+		{{Key: "request_id", Value: "r-1"}, {Key: "user", Value: 42}, {Key: "code", Value: 500}},
+		{{Key: "request_id", Value: "r-1"}, {Key: "user", Value: 7}},
+		{{Key: "request_id", Value: "r-1"}},
+		nil,
+	}
+	lines := capture.Lines()
+	if len(lines) != len(want) {
+		t.Fatalf("got %d records; want %d", len(lines), len(want))
+	}
+	for i, rec := range lines {
+		if !reflect.DeepEqual(rec.Fields, want[i]) {
+			t.Errorf("record %d (%s) fields = %v; want %v", i, rec.Message, rec.Fields, want[i])
+		}
+	}
+}
+
+// 测试 DebugField 只在 DEBUG 日志中求值并输出，INFO 日志中既不出现也不求值
+func TestDebugField(t *testing.T) {
+	log, capture := NewCapturing()
+	log.SetLevel(DEBUG)
+	calls := 0
+	reqLog := log.With("path", "/orders").Group("http").DebugField("headers", func() interface{} {
+		calls++
+		return "Accept: */*"
+	})
+
+	reqLog.Info("handled")
+	if calls != 0 {
+		t.Errorf("valueFunc called %d times for an INFO record; want 0", calls)
+	}
+	reqLog.Debug("handled in detail")
+	if calls != 1 {
+		t.Errorf("valueFunc called %d times after a DEBUG record; want 1", calls)
+	}
+
+	recs := capture.Lines()
+	if len(recs) != 2 {
+		t.Fatalf("got %d records; want 2", len(recs))
+	}
+	if f := flattenFields(recs[0].Fields); len(f) != 1 || f[0].Key != "path" {
+		t.Errorf("INFO fields = %v; want only path", f)
+	}
+	if f := flattenFields(recs[1].Fields); len(f) != 2 || f[1].Key != "http.headers" || f[1].Value != "Accept: */*" {
+		t.Errorf("DEBUG fields = %v; want path and http.headers", f)
+	}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// fileFailureThreshold 是判定日志文件持续不可写所需的连续写入失败次数
+const fileFailureThreshold = 3
+
+// writeFile 把一条日志写入 fileLogger，只在 write() 中调用。写入失败的日志以文件格式（不带颜色）改写到 stderr，
+// 连续失败 fileFailureThreshold 次后不再尝试该写入器，之后的文件日志都写到 stderr，
+// 并输出一次 WARN 说明原因；Reopen 或 Reconfigure 换用新的写入器后重新尝试文件。
+// onConsole 表示这条日志已输出到控制台，此时不再重复写到 stderr。
+func (l *Logger) writeFile(r *renderings, onConsole bool) {
+	format := l.formatsFor(r.msg).file()
+	fallback := func() {
+		if !onConsole {
+			io.WriteString(l.stderr, r.get(format))
+		}
+	}
+	if l.failedFile != nil && l.failedFile == l.fileLogger {
+		fallback()
+		return
+	}
+	_, err := l.fileLogger.Write([]byte(r.file(format)))
+	if err == nil {
+		l.fileErrors = 0
+		l.unsynced = true
+		if l.config.SyncOnError && r.msg.Level >= ERROR {
+			_ = syncWriter(l.fileLogger)
+		}
+		return
+	}
+	fallback()
+	l.fileErrors++
+	if l.fileErrors < fileFailureThreshold {
+		return
+	}
+	l.failedFile = l.fileLogger
+	l.fileErrors = 0
+	reason := "falling back to stderr"
+	if onConsole {
+		reason = "record already on console, not duplicated to stderr"
+	}
+	l.pending.Add(1)
+	l.write(logMsg{
+		Level:   WARN,
+		Message: fmt.Sprintf("log file is not writable, %s: %v", reason, err),
+		Time:    time.Now(),
+		Caller:  "logger",
+		Fields:  []Field{{Key: "path", Value: l.config.LogPath}},
+	})
+}
@@ -0,0 +1,533 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// JSONKeys 定义 JSON 输出中标准字段的名称，留空的字段使用默认名称
+type JSONKeys struct {
+	Time      string // 默认 "time"
+	Level     string // 默认 "level"
+	Message   string // 默认 "message"
+	Caller    string // 默认 "caller"
+	Component string // 默认 "component"
+	File      string // SplitCaller 时使用，默认 "file"
+	Line      string // SplitCaller 时使用，默认 "line"
+	Func      string // SplitCaller 时使用，默认 "func"
+}
+
+func (k JSONKeys) withDefaults() JSONKeys {
+	if k.Time == "" {
+		k.Time = "time"
+	}
+	if k.Level == "" {
+		k.Level = "level"
+	}
+	if k.Message == "" {
+		k.Message = "message"
+	}
+	if k.Caller == "" {
+		k.Caller = "caller"
+	}
+	if k.Component == "" {
+		k.Component = "component"
+	}
+	if k.File == "" {
+		k.File = "file"
+	}
+	if k.Line == "" {
+		k.Line = "line"
+	}
+	if k.Func == "" {
+		k.Func = "func"
+	}
+	return k
+}
+
+// TimePrecision 控制时间戳的精度。TimeDefault 保持原有格式（JSON 为 RFC3339 字符串）；
+// 其余取值在 JSON 中输出该精度的 Unix 纪元整数，在 plain 与 logfmt 中追加对应位数的小数秒。
+type TimePrecision int
+
+const (
+	TimeDefault TimePrecision = iota
+	TimeSeconds
+	TimeMillis
+	TimeMicros
+	TimeNanos
+)
+
+var timePrecisionNames = []string{"default", "seconds", "millis", "micros", "nanos"}
+
+func (p TimePrecision) MarshalText() ([]byte, error) {
+	if p < 0 || int(p) >= len(timePrecisionNames) {
+		return nil, fmt.Errorf("logger: invalid time precision %d", int(p))
+	}
+	return []byte(timePrecisionNames[p]), nil
+}
+
+func (p *TimePrecision) UnmarshalText(text []byte) error {
+	for i, name := range timePrecisionNames {
+		if strings.EqualFold(string(text), name) {
+			*p = TimePrecision(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("logger: unknown time precision %q", text)
+}
+
+// fraction 返回 time.Format 中对应精度的小数秒部分
+func (p TimePrecision) fraction() string {
+	switch p {
+	case TimeMillis:
+		return ".000"
+	case TimeMicros:
+		return ".000000"
+	case TimeNanos:
+		return ".000000000"
+	default:
+		return ""
+	}
+}
+
+// jsonTime 返回 JSON 中的时间值：默认为 RFC3339 字符串，否则为该精度的纪元整数
+func (p TimePrecision) jsonTime(t time.Time) interface{} {
+	switch p {
+	case TimeSeconds:
+		return t.Unix()
+	case TimeMillis:
+		return t.UnixMilli()
+	case TimeMicros:
+		return t.UnixMicro()
+	case TimeNanos:
+		return t.UnixNano()
+	default:
+		return t.Format(time.RFC3339)
+	}
+}
+
+func (l *Logger) formatLog(msg logMsg) string {
+	return l.formatLogAs(l.config.Format, msg)
+}
+
+func (c *Config) consoleFormat() Format { return c.formats().console() }
+
+func (c *Config) fileFormat() Format { return c.formats().file() }
+
+// formatSet 是决定输出格式的配置项，Clone 派生的 Logger 通过 logMsg.Formats 携带自己的一份
+type formatSet struct {
+	format        Format
+	consoleFormat *Format
+	fileFormat    *Format
+}
+
+func (c *Config) formats() formatSet {
+	return formatSet{format: c.Format, consoleFormat: c.ConsoleFormat, fileFormat: c.FileFormat}
+}
+
+func (s formatSet) console() Format {
+	if s.consoleFormat != nil {
+		return *s.consoleFormat
+	}
+	return s.format
+}
+
+func (s formatSet) file() Format {
+	if s.fileFormat != nil {
+		return *s.fileFormat
+	}
+	return s.format
+}
+
+// formatsFor 返回这条日志使用的格式：Clone 设置的优先，否则为配置中的
+func (l *Logger) formatsFor(msg logMsg) formatSet {
+	if msg.Formats != nil {
+		return *msg.Formats
+	}
+	return l.config.formats()
+}
+
+// renderings 缓存同一条日志在各格式下的输出，多个目标使用相同格式时只格式化一次
+type renderings struct {
+	l    *Logger
+	msg  logMsg
+	done [numFormats]bool
+	out  [numFormats]string
+}
+
+func (r *renderings) get(f Format) string {
+	if f < 0 || int(f) >= len(r.out) {
+		return r.l.formatLogAs(f, r.msg)
+	}
+	if !r.done[f] {
+		r.out[f] = r.l.formatLogAs(f, r.msg)
+		r.done[f] = true
+	}
+	return r.out[f]
+}
+
+// console 返回控制台的输出：在 ConsoleFormat 的渲染结果（可带 marker 前缀）外包上等级颜色。
+// 颜色只在这里添加，文件等其他目标始终使用 get 返回的不含转义序列的内容。
+func (r *renderings) console(marker string) string {
+	out := marker + r.l.forTarget(OutputConsole, r.get(r.l.formatsFor(r.msg).console()))
+	if r.msg.Raw {
+		return out
+	}
+	return r.l.colorize(r.msg.Level, out)
+}
+
+// file 返回写入日志文件（主文件与白名单文件）的内容
+func (r *renderings) file(f Format) string {
+	return r.l.forTarget(OutputFile, r.get(f))
+}
+
+// jsonl 返回写入 JSONLPath 的内容：始终为内置 JSON，设置了 Formatter 也不使用
+func (r *renderings) jsonl() string {
+	if r.l.config.Formatter == nil {
+		return r.file(FormatJSON)
+	}
+	msg := r.msg
+	msg.Fields = r.l.maskFields(msg.Fields)
+	return r.l.forTarget(OutputFile, r.l.formatJSON(msg))
+}
+
+// bare 返回去掉行尾分隔符的内容，供事件日志、os_log 等按条记录的目标使用
+func (r *renderings) bare(f Format) string {
+	return strings.TrimSuffix(r.get(f), r.l.lineSeparator())
+}
+
+// lineSeparator 返回内置格式追加在每条日志末尾的分隔符，默认 "\n"
+func (l *Logger) lineSeparator() string {
+	if l.config.LineSeparator == "" {
+		return "\n"
+	}
+	return l.config.LineSeparator
+}
+
+// forTarget 为 OmitLineSeparator 中的目标去掉行尾的分隔符
+func (l *Logger) forTarget(t OutputTarget, line string) string {
+	if l.config.OmitLineSeparator&t == 0 {
+		return line
+	}
+	return strings.TrimSuffix(line, l.lineSeparator())
+}
+
+// formatLogAs 按指定格式渲染日志；设置了 Formatter 时忽略 f
+func (l *Logger) formatLogAs(f Format, msg logMsg) string {
+	if msg.Raw {
+		return msg.Message + l.lineSeparator()
+	}
+	msg.Fields = l.maskFields(msg.Fields)
+	if l.config.Formatter != nil {
+		return l.config.Formatter(msg.record())
+	}
+	switch f {
+	case FormatJSON:
+		return l.formatJSON(msg)
+	case FormatLogfmt:
+		return l.formatLogfmt(msg)
+	case FormatECS:
+		return l.formatECS(msg)
+	default:
+		return l.formatPlain(msg)
+	}
+}
+
+// formatJSON 按固定顺序输出：time、level、caller、message、component，之后是结构化字段；
+// caller、component 为空时省略，SplitCaller 时 caller 换成 file、line、func 三个字段。
+// 与标准字段或前面字段重名的结构化字段会被忽略。
+func (l *Logger) formatJSON(msg logMsg) string {
+	keys := l.config.JSONKeys.withDefaults()
+	var obj jsonObject
+	obj.add(keys.Time, l.config.TimePrecision.jsonTime(msg.Time))
+	obj.add(keys.Level, levelToStr(msg.Level))
+	if l.config.SplitCaller && msg.File != "" {
+		obj.add(keys.File, msg.File)
+		obj.add(keys.Line, msg.Line)
+		obj.add(keys.Func, msg.Func)
+	} else if msg.Caller != "" {
+		obj.add(keys.Caller, msg.Caller)
+	}
+	obj.add(keys.Message, msg.Message)
+	if msg.Component != "" {
+		obj.add(keys.Component, msg.Component)
+	}
+	if msg.Seq != 0 {
+		obj.add("seq", msg.Seq)
+	}
+	for _, f := range mergeGroups(msg.Fields) {
+		obj.add(f.Key, f.Value)
+	}
+	return obj.String() + l.lineSeparator()
+}
+
+func (l *Logger) formatPlain(msg logMsg) string {
+	var sb strings.Builder
+	if msg.Seq != 0 {
+		fmt.Fprintf(&sb, "#%d ", msg.Seq)
+	}
+	level := "[" + levelToStr(msg.Level) + "]"
+	if l.config.CompactLevel {
+		// 单字母等级：T/D/I/W/E/F
+		level = levelToStr(msg.Level)[:1]
+	}
+	fmt.Fprintf(&sb, "%s %s ",
+		level,
+		msg.Time.Format("2006-01-02 15:04:05"+l.config.TimePrecision.fraction()),
+	)
+	clean := func(s string) string { return s }
+	if l.config.SanitizeNewlines {
+		clean = escapeControl
+	}
+	if msg.Caller != "" {
+		sb.WriteString(clean(l.plainCaller(msg)))
+		sb.WriteByte(' ')
+	}
+	if msg.Component != "" {
+		fmt.Fprintf(&sb, "[%s] ", clean(msg.Component))
+	}
+	sb.WriteString(clean(msg.Message))
+	for _, f := range flattenFields(msg.Fields) {
+		sb.WriteByte(' ')
+		sb.WriteString(clean(f.Key))
+		sb.WriteByte('=')
+		sb.WriteString(clean(plainValue(f.Value)))
+	}
+	sb.WriteString(l.lineSeparator())
+	return sb.String()
+}
+
+// escapeControl 把换行、回车、制表符转义为 \n、\r、\t，其余控制字符（含 U+2028/U+2029 行分隔符）
+// 转义为 \xHH 或 \uHHHH，防止伪造日志行
+func escapeControl(s string) string {
+	if strings.IndexFunc(s, needsEscape) < 0 {
+		return s
+	}
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case !needsEscape(r):
+			sb.WriteRune(r)
+		case r < 0x80:
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		default:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		}
+	}
+	return sb.String()
+}
+
+func needsEscape(r rune) bool {
+	return unicode.IsControl(r) || r == '\u2028' || r == '\u2029'
+}
+
+const maskedValue = "***"
+
+// maskFields 返回把敏感字段值替换为 *** 后的字段副本，无需脱敏时原样返回
+func (l *Logger) maskFields(fields []Field) []Field {
+	if len(l.config.MaskKeys) == 0 || len(fields) == 0 {
+		return fields
+	}
+	var masked []Field
+	for i, f := range fields {
+		var value interface{}
+		g, isGroup := f.Value.(fieldGroup)
+		switch {
+		case l.isMaskedKey(f.Key):
+			value = maskedValue
+		case isGroup && l.hasMaskedKey(g):
+			value = fieldGroup(l.maskFields(g))
+		default:
+			continue
+		}
+		if masked == nil {
+			masked = append([]Field(nil), fields...)
+		}
+		masked[i].Value = value
+	}
+	if masked == nil {
+		return fields
+	}
+	return masked
+}
+
+// hasMaskedKey 报告字段（含嵌套分组）中是否有需要脱敏的 key
+func (l *Logger) hasMaskedKey(fields []Field) bool {
+	for _, f := range fields {
+		if l.isMaskedKey(f.Key) {
+			return true
+		}
+		if g, ok := f.Value.(fieldGroup); ok && l.hasMaskedKey(g) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Logger) isMaskedKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, m := range l.config.MaskKeys {
+		if m != "" && strings.Contains(key, strings.ToLower(m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// plainValueLimit 是 plain 与 logfmt 中单个字段值的最大字节数，超出部分截断
+const plainValueLimit = 1024
+
+// plainValue 把字段值渲染为文本：结构体、map、切片等复合值（及指向它们的指针）序列化为 JSON，
+// 嵌套的指针也展开为内容；无法序列化时退回 %+v，其余值使用 %v。
+// 实现了 Error 或 String 方法的值总是使用该方法；time.Duration 见 humanDuration。
+func plainValue(v interface{}) string {
+	if d, ok := v.(time.Duration); ok {
+		return humanDuration(d)
+	}
+	var s string
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		s = compositeValue(v)
+	default:
+		s = fmt.Sprintf("%v", v)
+	}
+	return truncateMessage(s, plainValueLimit)
+}
+
+func compositeValue(v interface{}) string {
+	switch v.(type) {
+	case error, fmt.Stringer:
+		return fmt.Sprintf("%v", v)
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// formatLogfmt 输出 level=info ts=... caller=... msg="..." 形式，结构化字段追加为 key=value
+func (l *Logger) formatLogfmt(msg logMsg) string {
+	var sb strings.Builder
+	writeLogfmtPair(&sb, "level", strings.ToLower(levelToStr(msg.Level)))
+	writeLogfmtPair(&sb, "ts", msg.Time.Format("2006-01-02T15:04:05"+l.config.TimePrecision.fraction()+"Z07:00"))
+	if msg.Caller != "" {
+		writeLogfmtPair(&sb, "caller", msg.Caller)
+	}
+	if msg.Component != "" {
+		writeLogfmtPair(&sb, "component", msg.Component)
+	}
+	if msg.Seq != 0 {
+		writeLogfmtPair(&sb, "seq", strconv.FormatUint(msg.Seq, 10))
+	}
+	writeLogfmtPair(&sb, "msg", msg.Message)
+	for _, f := range flattenFields(msg.Fields) {
+		writeLogfmtPair(&sb, f.Key, plainValue(f.Value))
+	}
+	sb.WriteString(l.lineSeparator())
+	return sb.String()
+}
+
+func writeLogfmtPair(sb *strings.Builder, key, value string) {
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(logfmtKey(key))
+	sb.WriteByte('=')
+	if logfmtNeedsQuote(value) {
+		sb.WriteString(strconv.Quote(value))
+	} else {
+		sb.WriteString(value)
+	}
+}
+
+// logfmtKey 去掉 key 中会破坏 key=value 结构的字符
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func logfmtNeedsQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonObject 按写入顺序拼接 JSON 对象，重复的 key 只保留第一次出现的值
+type jsonObject struct {
+	buf  bytes.Buffer
+	seen map[string]struct{}
+}
+
+func (o *jsonObject) add(key string, value interface{}) {
+	if d, ok := value.(time.Duration); ok {
+		key, value = key+"_ms", durationMillis(d)
+	}
+	if _, dup := o.seen[key]; dup {
+		return
+	}
+	if o.seen == nil {
+		o.seen = make(map[string]struct{})
+	}
+	o.seen[key] = struct{}{}
+
+	v, err := json.Marshal(jsonValue(value))
+	if err != nil {
+		// 无法序列化的值退化为字符串，保证整行仍是合法 JSON
+		v, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	k, _ := json.Marshal(key)
+
+	if o.buf.Len() == 0 {
+		o.buf.WriteByte('{')
+	} else {
+		o.buf.WriteByte(',')
+	}
+	o.buf.Write(k)
+	o.buf.WriteByte(':')
+	o.buf.Write(v)
+}
+
+// jsonValue 保留字段值的 Go 类型交给 json.Marshal：数值、布尔为 JSON 原生类型，nil 为 null，
+// time.Time 与实现了 json.Marshaler 的值按其自身规则序列化；未实现 json.Marshaler 的 error 输出 Error() 字符串，
+// 否则会被序列化为 {}。
+func jsonValue(value interface{}) interface{} {
+	if err, ok := value.(error); ok {
+		if _, ok := value.(json.Marshaler); !ok {
+			return err.Error()
+		}
+	}
+	return value
+}
+
+func (o *jsonObject) String() string {
+	if o.buf.Len() == 0 {
+		return "{}"
+	}
+	return o.buf.String() + "}"
+}
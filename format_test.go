@@ -0,0 +1,573 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试 JSON 输出的字段顺序固定为 time、level、caller、message、component，之后是结构化字段
+func TestFormatJSONKeyOrder(t *testing.T) {
+	log := &Logger{core: &core{config: Config{Format: FormatJSON}}}
+	out := log.formatLog(logMsg{
+		Level:     INFO,
+		Message:   "hello",
+		Time:      time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		Caller:    "main.go:1 main.main",
+		Component: "db",
+		Fields:    []Field{{Key: "zeta", Value: 1}, {Key: "alpha", Value: 2}, {Key: "level", Value: "dup"}},
+	})
+
+	want := `{"time":"2024-01-15T08:00:00Z","level":"INFO","caller":"main.go:1 main.main",` +
+		`"message":"hello","component":"db","zeta":1,"alpha":2}` + "\n"
+	if out != want {
+		t.Errorf("formatJSON =\n%s\nwant\n%s", out, want)
+	}
+}
+
+// 测试 MaskKeys 对匹配的字段脱敏（不区分大小写、支持子串），其余字段原样输出
+func TestMaskKeys(t *testing.T) {
+	msg := logMsg{
+		Level:   INFO,
+		Message: "login",
+		Time:    time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		Fields: []Field{
+			{Key: "user", Value: "alice"},
+			{Key: "Password", Value: "hunter2"},
+			{Key: "access_token", Value: "abc"},
+			{Key: "client_SECRET", Value: "xyz"},
+		},
+	}
+	cfg := Config{MaskKeys: []string{"password", "TOKEN", "secret"}}
+
+	cfg.Format = FormatPlain
+	plain := (&Logger{core: &core{config: cfg}}).formatLog(msg)
+	if !strings.HasSuffix(plain, "login user=alice Password=*** access_token=*** client_SECRET=***\n") {
+		t.Errorf("plain output = %q", plain)
+	}
+
+	cfg.Format = FormatJSON
+	out := (&Logger{core: &core{config: cfg}}).formatLog(msg)
+	if !strings.Contains(out, `"user":"alice","Password":"***","access_token":"***","client_SECRET":"***"`) {
+		t.Errorf("JSON output = %s", out)
+	}
+	for _, secret := range []string{"hunter2", "abc", "xyz"} {
+		if strings.Contains(plain, secret) || strings.Contains(out, secret) {
+			t.Errorf("secret %q leaked", secret)
+		}
+	}
+
+	if msg.Fields[1].Value != "hunter2" {
+		t.Errorf("original fields were modified")
+	}
+}
+
+// 测试 logfmt 格式对包含空格、引号的值正确加引号并转义
+func TestFormatLogfmt(t *testing.T) {
+	log := &Logger{core: &core{config: Config{Format: FormatLogfmt}}}
+	out := log.formatLog(logMsg{
+		Level:   WARN,
+		Message: `disk "sda" almost full`,
+		Time:    time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		Caller:  "main.go:1 main.main",
+		Fields: []Field{
+			{Key: "used", Value: 93},
+			{Key: "path", Value: `C:\data`},
+			{Key: "ok", Value: true},
+			{Key: "note", Value: ""},
+		},
+	})
+
+	want := `level=warn ts=2024-01-15T08:00:00Z caller="main.go:1 main.main" ` +
+		`msg="disk \"sda\" almost full" used=93 path="C:\\data" ok=true note=""` + "\n"
+	if out != want {
+		t.Errorf("formatLogfmt =\n%s\nwant\n%s", out, want)
+	}
+}
+
+// 测试自定义 Formatter 代替内置格式，且输出原样写入文件目标
+func TestCustomFormatter(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{
+		Synchronous: true,
+		MinLevel:    INFO,
+		Format:      FormatJSON,
+		MaskKeys:    []string{"password"},
+		Formatter: func(r LogRecord) string {
+			var b strings.Builder
+			b.WriteString(r.Level.String() + "|" + r.Component + "|" + r.Message)
+			for _, f := range r.Fields {
+				b.WriteString("|" + f.Key + "=" + fmt.Sprint(f.Value))
+			}
+			return b.String() + "\n"
+		},
+	})
+	log.Named("auth").Infow("login", "user", "alice", "password", "secret")
+	log.Close()
+
+	want := "INFO|auth|login|user=alice|password=***\n"
+	if out := buf.String(); out != want {
+		t.Errorf("output = %q; want %q", out, want)
+	}
+}
+
+// 测试 ConsoleFormat 与 FileFormat 分别控制两个目标的格式
+func TestPerTargetFormat(t *testing.T) {
+	plain, jsonFmt := FormatPlain, FormatJSON
+	log, file := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, ConsoleFormat: &plain, FileFormat: &jsonFmt})
+	console := &syncBuffer{}
+	log.stdout = console
+	log.config.Targets |= OutputConsole
+	log.config.LevelColors = map[Level]string{INFO: "\033[0m"}
+	log.Infow("same message", "k", 1)
+	log.Close()
+
+	if out := console.String(); !strings.Contains(out, "[INFO] ") || !strings.Contains(out, "same message k=1") {
+		t.Errorf("console output = %q; want plain", out)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(file.String()), &data); err != nil {
+		t.Fatalf("file output %q is not JSON: %v", file.String(), err)
+	}
+	if data["message"] != "same message" || data["k"] != float64(1) {
+		t.Errorf("file JSON = %v", data)
+	}
+}
+
+// 测试多个目标格式相同时每条日志只格式化一次
+func TestRenderingsFormatOnce(t *testing.T) {
+	calls := 0
+	log := &Logger{core: &core{config: Config{Formatter: func(r LogRecord) string {
+		calls++
+		return r.Message + "\n"
+	}}}}
+	r := renderings{l: log, msg: logMsg{Message: "once"}}
+	for i := 0; i < 3; i++ {
+		if out := r.get(FormatPlain); out != "once\n" {
+			t.Fatalf("get = %q", out)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("formatted %d times; want 1", calls)
+	}
+}
+
+// 测试各 TimePrecision 在 JSON 与 plain 中的时间表示
+func TestTimePrecision(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 8, 0, 0, 123456789, time.UTC)
+	cases := []struct {
+		precision TimePrecision
+		json      string
+		plain     string
+	}{
+		{TimeDefault, `"2024-01-15T08:00:00Z"`, "2024-01-15 08:00:00 "},
+		{TimeSeconds, `1705305600`, "2024-01-15 08:00:00 "},
+		{TimeMillis, `1705305600123`, "2024-01-15 08:00:00.123 "},
+		{TimeMicros, `1705305600123456`, "2024-01-15 08:00:00.123456 "},
+		{TimeNanos, `1705305600123456789`, "2024-01-15 08:00:00.123456789 "},
+	}
+	for _, c := range cases {
+		msg := logMsg{Level: INFO, Message: "tick", Time: ts}
+		jsonLog := &Logger{core: &core{config: Config{Format: FormatJSON, TimePrecision: c.precision}}}
+		if out := jsonLog.formatLog(msg); !strings.HasPrefix(out, `{"time":`+c.json+`,`) {
+			t.Errorf("precision %d JSON = %s; want time %s", c.precision, out, c.json)
+		}
+		plainLog := &Logger{core: &core{config: Config{TimePrecision: c.precision}}}
+		if out := plainLog.formatLog(msg); out != "[INFO] "+c.plain+"tick\n" {
+			t.Errorf("precision %d plain = %q; want time %q", c.precision, out, c.plain)
+		}
+	}
+
+	var p TimePrecision
+	if err := p.UnmarshalText([]byte("Micros")); err != nil || p != TimeMicros {
+		t.Errorf("UnmarshalText(Micros) = %v, %v", p, err)
+	}
+}
+
+// 测试 panic 日志使用相同的时间精度
+func TestTimePrecisionPanic(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG, Format: FormatJSON, TimePrecision: TimeNanos})
+	func() {
+		defer func() { log.logPanic(recover(), 2) }()
+		panic("boom")
+	}()
+	log.Close()
+	var data map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(buf.String()))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if n, ok := data["time"].(json.Number); !ok || len(n.String()) != 19 {
+		t.Errorf("panic time = %v; want a nanosecond epoch", data["time"])
+	}
+}
+
+type testAddress struct {
+	City string
+	Zip  int
+}
+
+type testUser struct {
+	Name    string
+	Address *testAddress
+	Tags    []string
+}
+
+// unmarshalable 含有 channel，无法序列化为 JSON
+type unmarshalable struct{ C chan int }
+
+// 测试嵌套结构体、map 在 plain 中保留字段名、在 JSON 中原生序列化，超长值被截断，无法序列化的值退化为字符串
+func TestCompositeFieldValues(t *testing.T) {
+	user := testUser{Name: "alice", Address: &testAddress{City: "Paris", Zip: 75001}, Tags: []string{"a", "b"}}
+	msg := logMsg{
+		Level:   INFO,
+		Message: "composite",
+		Time:    time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		Fields: []Field{
+			{Key: "user", Value: user},
+			{Key: "limits", Value: map[string]int{"b": 2, "a": 1}},
+			{Key: "err", Value: errors.New("boom")},
+			{Key: "big", Value: strings.Repeat("x", 2000)},
+			{Key: "bad", Value: unmarshalable{}},
+		},
+	}
+
+	plain := (&Logger{core: &core{config: Config{}}}).formatLog(msg)
+	for _, want := range []string{
+		`user={"Name":"alice","Address":{"City":"Paris","Zip":75001},"Tags":["a","b"]}`,
+		`limits={"a":1,"b":2}`,
+		"err=boom",
+		"big=" + strings.Repeat("x", plainValueLimit) + "...[truncated 976 bytes]",
+		"bad={C:<nil>}",
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("plain output missing %q:\n%s", want, plain)
+		}
+	}
+	if addr := plainValue(&testAddress{City: "Paris", Zip: 75001}); addr != `{"City":"Paris","Zip":75001}` {
+		t.Errorf("plainValue(*struct) = %q", addr)
+	}
+
+	out := (&Logger{core: &core{config: Config{Format: FormatJSON}}}).formatLog(msg)
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	u, _ := data["user"].(map[string]interface{})
+	if addr, _ := u["Address"].(map[string]interface{}); u["Name"] != "alice" || addr["City"] != "Paris" {
+		t.Errorf("user = %v; want nested object", data["user"])
+	}
+	if limits, _ := data["limits"].(map[string]interface{}); limits["a"] != float64(1) {
+		t.Errorf("limits = %v; want object", data["limits"])
+	}
+	if data["bad"] != "{<nil>}" {
+		t.Errorf("bad = %v; want %%v fallback string", data["bad"])
+	}
+}
+
+// 测试 SanitizeNewlines 时消息、caller 和字段中的换行与控制字符被转义，输出仍是一行
+func TestSanitizeNewlines(t *testing.T) {
+	log := &Logger{core: &core{config: Config{SanitizeNewlines: true}}}
+	out := log.formatLog(logMsg{
+		Level:   INFO,
+		Message: "login ok\n[ERROR] 2024-01-15 08:00:00 forged\r\tbell\a\u2028end",
+		Time:    time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		Caller:  "main.go:1\nmain.main",
+		Fields:  []Field{{Key: "user\n", Value: "bob\x1b[31m"}},
+	})
+	want := `[INFO] 2024-01-15 08:00:00 main.go:1\nmain.main login ok\n[ERROR] 2024-01-15 08:00:00 forged\r\tbell\x07\u2028end user\n=bob\x1b[31m` + "\n"
+	if out != want {
+		t.Errorf("sanitized output =\n%q\nwant\n%q", out, want)
+	}
+
+	// 未开启时保持原样
+	raw := (&Logger{core: &core{config: Config{}}}).formatLog(logMsg{Level: INFO, Message: "a\nb"})
+	if !strings.Contains(raw, "a\nb") {
+		t.Errorf("unsanitized output = %q", raw)
+	}
+}
+
+// FuzzSanitizeNewlines 检查任意消息在 SanitizeNewlines 下都只产生一行
+func FuzzSanitizeNewlines(f *testing.F) {
+	for _, seed := range []string{"plain", "a\nb", "\r\n\x00\x1b", "\u2028\u2029\u0085", "\xff\xfe"} {
+		f.Add(seed)
+	}
+	log := &Logger{core: &core{config: Config{SanitizeNewlines: true}}}
+	f.Fuzz(func(t *testing.T, msg string) {
+		out := log.formatLog(logMsg{Level: INFO, Message: msg, Fields: []Field{{Key: msg, Value: msg}}})
+		body := strings.TrimSuffix(out, "\n")
+		if strings.ContainsAny(body, "\n\r\u2028\u2029\u0085") {
+			t.Errorf("output for %q spans multiple lines: %q", msg, out)
+		}
+	})
+}
+
+// 测试 time.Duration 字段在 JSON 中为毫秒数值，在 plain 中为可读形式
+func TestDurationField(t *testing.T) {
+	run := func(format Format) string {
+		log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, Format: format})
+		log.WithDuration("duration", 123*time.Millisecond).Infow("done",
+			"short", 250*time.Microsecond,
+			"long", 1000*time.Hour+1500*time.Microsecond,
+		)
+		log.Close()
+		return buf.String()
+	}
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal([]byte(run(FormatJSON)), &rec); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]float64{"duration_ms": 123, "short_ms": 0.25, "long_ms": 3600000001.5} {
+		if got, ok := rec[key].(float64); !ok || got != want {
+			t.Errorf("JSON %s = %v; want %v", key, rec[key], want)
+		}
+	}
+	if _, ok := rec["duration"]; ok {
+		t.Error("JSON should only contain duration_ms")
+	}
+
+	plain := run(FormatPlain)
+	for _, want := range []string{"duration=123ms", "short=250µs", "long=1000h0m0.002s"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("plain output %q missing %q", plain, want)
+		}
+	}
+}
+
+// 测试控制台与文件同时输出时只有控制台带颜色，文件内容不含转义序列
+func TestColorsOnlyOnConsole(t *testing.T) {
+	console := &syncBuffer{}
+	log, file := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, ConsoleWriter: console})
+	log.config.Targets |= OutputConsole
+	log.Info("both targets")
+	log.Error("error line")
+	func() {
+		defer func() { log.logPanic(recover(), 2) }()
+		panic("colored boom")
+	}()
+	log.Close()
+
+	for _, want := range []string{"both targets", "error line", "colored boom"} {
+		if !strings.Contains(file.String(), want) || !strings.Contains(console.String(), want) {
+			t.Errorf("%q missing from file %q or console %q", want, file.String(), console.String())
+		}
+	}
+	if strings.Contains(file.String(), "\x1b") {
+		t.Errorf("file output contains escape codes: %q", file.String())
+	}
+	if strings.Count(console.String(), "\x1b[0m") != 3 {
+		t.Errorf("console output = %q; want three colorized records", console.String())
+	}
+}
+
+// jsonStatus 实现 json.Marshaler，测试自定义序列化不被改写
+type jsonStatus int
+
+func (s jsonStatus) MarshalJSON() ([]byte, error) {
+	return []byte(`{"code":` + strconv.Itoa(int(s)) + `}`), nil
+}
+
+// 测试 JSON 中数值与布尔字段为原生类型，nil、time.Time、json.Marshaler 与 error 按各自规则输出，plain 中全部为文本
+func TestJSONNativeFieldTypes(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	msg := logMsg{
+		Level:   INFO,
+		Message: "typed",
+		Time:    ts,
+		Fields: []Field{
+			{Key: "status", Value: 200},
+			{Key: "ok", Value: true},
+			{Key: "ratio", Value: 0.5},
+			{Key: "count", Value: uint64(7)},
+			{Key: "none", Value: nil},
+			{Key: "at", Value: ts},
+			{Key: "custom", Value: jsonStatus(3)},
+			{Key: "err", Value: errors.New("boom")},
+		},
+	}
+
+	out := (&Logger{core: &core{config: Config{Format: FormatJSON}}}).formatLog(msg)
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if data["status"] != float64(200) || data["ratio"] != 0.5 || data["count"] != float64(7) {
+		t.Errorf("numbers = %v, %v, %v; want JSON numbers", data["status"], data["ratio"], data["count"])
+	}
+	if data["ok"] != true {
+		t.Errorf("ok = %#v; want JSON true", data["ok"])
+	}
+	if v, present := data["none"]; !present || v != nil {
+		t.Errorf("none = %#v (present %v); want null", v, present)
+	}
+	if data["at"] != "2024-01-15T08:00:00Z" {
+		t.Errorf("at = %v; want RFC 3339 time", data["at"])
+	}
+	if c, _ := data["custom"].(map[string]interface{}); c["code"] != float64(3) {
+		t.Errorf("custom = %v; want the MarshalJSON output", data["custom"])
+	}
+	if data["err"] != "boom" {
+		t.Errorf("err = %#v; want the error message", data["err"])
+	}
+
+	plain := (&Logger{core: &core{config: Config{}}}).formatLog(msg)
+	if !strings.Contains(plain, "status=200 ok=true ratio=0.5 count=7 none=<nil>") || !strings.Contains(plain, "err=boom") {
+		t.Errorf("plain output = %q", plain)
+	}
+}
+
+// 测试 CompactLevel 时 plain 中各等级渲染为单个字母，JSON 仍为完整名称
+func TestCompactLevel(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	compact := &Logger{core: &core{config: Config{CompactLevel: true}}}
+	full := &Logger{core: &core{config: Config{}}}
+	jsonLog := &Logger{core: &core{config: Config{Format: FormatJSON, CompactLevel: true}}}
+	for level, letter := range map[Level]string{TRACE: "T", DEBUG: "D", INFO: "I", WARN: "W", ERROR: "E", FATAL: "F"} {
+		msg := logMsg{Level: level, Message: "m", Time: ts}
+		if got, want := compact.formatLog(msg), letter+" 2024-01-15 08:00:00 m\n"; got != want {
+			t.Errorf("compact %v = %q; want %q", level, got, want)
+		}
+		if got, want := full.formatLog(msg), "["+level.String()+"] 2024-01-15 08:00:00 m\n"; got != want {
+			t.Errorf("full %v = %q; want %q", level, got, want)
+		}
+		if got := jsonLog.formatLog(msg); !strings.Contains(got, `"level":"`+level.String()+`"`) {
+			t.Errorf("JSON %v = %q; want the full level name", level, got)
+		}
+	}
+}
+
+// 测试 LineSeparator 用于 plain、JSON 与 WriteRaw，OmitLineSeparator 中的目标不追加分隔符
+func TestLineSeparator(t *testing.T) {
+	for _, format := range []Format{FormatPlain, FormatJSON, FormatLogfmt} {
+		log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, Format: format, LineSeparator: "\r\n"})
+		log.Info("first")
+		log.Info("second")
+		log.WriteRaw(INFO, "raw line")
+		log.Close()
+		records := strings.Split(buf.String(), "\r\n")
+		if len(records) != 4 || records[3] != "" || !strings.Contains(records[1], "second") || records[2] != "raw line" {
+			t.Errorf("format %v: output = %q; want three records each ending in \\r\\n", format, buf.String())
+		}
+		if strings.Count(buf.String(), "\n") != 3 {
+			t.Errorf("format %v: output = %q; want no bare newlines", format, buf.String())
+		}
+	}
+
+	console := &syncBuffer{}
+	log, file := newBufferLogger(t, Config{
+		Synchronous:       true,
+		MinLevel:          INFO,
+		Format:            FormatJSON,
+		LineSeparator:     "\x1e",
+		OmitLineSeparator: OutputFile,
+		ConsoleWriter:     console,
+	})
+	log.config.Targets |= OutputConsole
+	log.Info("framed")
+	log.Info("framed")
+	log.Close()
+	if strings.Contains(file.String(), "\x1e") || strings.Count(file.String(), "}{") != 1 {
+		t.Errorf("file output = %q; want records without separators", file.String())
+	}
+	if strings.Count(console.String(), "}\x1e") != 2 {
+		t.Errorf("console output = %q; want each record followed by the separator", console.String())
+	}
+}
+
+// 测试 CallerPathStyle 的三种形式对同一调用位置输出的路径
+func TestCallerPathStyle(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	root := filepath.Dir(filepath.Dir(file))
+	rel := strings.TrimPrefix(file, filepath.ToSlash(root)+"/")
+	for _, tc := range []struct {
+		style CallerPathStyle
+		root  string
+		want  string
+	}{
+		{CallerShort, "", "format_test.go:"},
+		{CallerRelative, root, rel + ":"},
+		{CallerRelative, filepath.Join(root, "elsewhere"), file + ":"},
+		{CallerAbsolute, root, file + ":"},
+	} {
+		log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, CallerPathStyle: tc.style, CallerRoot: tc.root})
+		log.Info("here")
+		log.Close()
+		_, _, line, _ := runtime.Caller(0)
+		want := fmt.Sprintf("%s%d logger.TestCallerPathStyle here", tc.want, line-2)
+		if got := buf.String(); !strings.Contains(got, want) {
+			t.Errorf("style %v root %q: output = %q; want %q", tc.style, tc.root, got, want)
+		}
+	}
+
+	var style CallerPathStyle
+	if err := style.UnmarshalText([]byte("Relative")); err != nil || style != CallerRelative {
+		t.Errorf("UnmarshalText(Relative) = %v, %v", style, err)
+	}
+	if text, err := CallerAbsolute.MarshalText(); err != nil || string(text) != "absolute" {
+		t.Errorf("MarshalText(CallerAbsolute) = %q, %v", text, err)
+	}
+}
+
+// 测试 IncludeSequence 分配的序号严格递增且没有空缺，plain 中作为行首前缀
+func TestIncludeSequence(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{MinLevel: INFO, Format: FormatJSON, IncludeSequence: true})
+	for i := 0; i < 50; i++ {
+		log.Infow("event", "i", i)
+	}
+	log.Close()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 50 {
+		t.Fatalf("got %d lines; want 50", len(lines))
+	}
+	for i, line := range lines {
+		var rec struct{ Seq uint64 }
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if rec.Seq != uint64(i+1) {
+			t.Errorf("line %d: seq = %d; want %d", i, rec.Seq, i+1)
+		}
+	}
+
+	plain := &Logger{core: &core{config: Config{}}}
+	msg := logMsg{Level: INFO, Message: "m", Time: time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC), Seq: 7}
+	if got, want := plain.formatLog(msg), "#7 [INFO] 2024-01-15 08:00:00 m\n"; got != want {
+		t.Errorf("plain = %q; want %q", got, want)
+	}
+}
+
+// 测试 FormatECS 按 Elastic Common Schema 的字段路径输出已知记录
+func TestFormatECS(t *testing.T) {
+	log := &Logger{core: &core{config: Config{Format: FormatECS}}}
+	msg := logMsg{
+		Level:     WARN,
+		Message:   "disk almost full",
+		Time:      time.Date(2024, 1, 15, 16, 0, 0, 123e6, time.FixedZone("CST", 8*3600)),
+		Caller:    "disk.go:42 storage.check",
+		File:      "disk.go",
+		Line:      42,
+		Func:      "storage.check",
+		Component: "storage",
+		Seq:       9,
+		Fields:    []Field{{Key: EventField, Value: "disk.usage.high"}, {Key: "used_pct", Value: 93}},
+	}
+	want := `{"@timestamp":"2024-01-15T08:00:00.123Z",` +
+		`"log":{"level":"warn","logger":"storage","origin":{"file":{"name":"disk.go","line":42},"function":"storage.check"}},` +
+		`"message":"disk almost full","ecs":{"version":"8.11.0"},` +
+		`"event":{"action":"disk.usage.high","sequence":9},"used_pct":93}` + "\n"
+	if got := log.formatLog(msg); got != want {
+		t.Errorf("ECS output =\n%s\nwant\n%s", got, want)
+	}
+
+	var f Format
+	if err := f.UnmarshalText([]byte("ECS")); err != nil || f != FormatECS {
+		t.Errorf("UnmarshalText(ECS) = %v, %v", f, err)
+	}
+}
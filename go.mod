@@ -2,4 +2,7 @@ module github.com/xiangxu05/logger
 
 go 1.24.4
 
-require gopkg.in/natefinch/lumberjack.v2 v2.2.1
+require (
+	golang.org/x/sys v0.22.0
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+)
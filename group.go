@@ -0,0 +1,100 @@
+package logger
+
+// fieldGroup 是 Group 产生的一组嵌套字段，作为 Field.Value 出现。
+// JSON 中输出为嵌套对象，plain/logfmt 中展开为 name.key=value。
+type fieldGroup []Field
+
+// MarshalJSON 按字段顺序输出嵌套对象，重名字段保留第一个
+func (g fieldGroup) MarshalJSON() ([]byte, error) {
+	var obj jsonObject
+	for _, f := range mergeGroups(g) {
+		obj.add(f.Key, f.Value)
+	}
+	return []byte(obj.String()), nil
+}
+
+// Group 返回一个派生 Logger，之后通过它添加的字段（With 或 Infow 等的键值对）都嵌套在 name 之下，
+// 例如 log.Group("http").Infow("done", "status", 200) 在 JSON 中输出 {"http":{"status":200}}。
+// 嵌套调用 Group 得到多层结构；Group 之前已附加的字段不受影响。
+func (l *Logger) Group(name string) *Logger {
+	if l.nop || name == "" {
+		return l
+	}
+	child := *l
+	child.groups = make([]string, 0, len(l.groups)+1)
+	child.groups = append(append(child.groups, l.groups...), name)
+	return &child
+}
+
+// nestFields 把 fields 按 groups 由内向外包装；没有字段时返回 nil，不产生空分组
+func nestFields(groups []string, fields []Field) []Field {
+	if len(groups) == 0 || len(fields) == 0 {
+		return fields
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		fields = []Field{{Key: groups[i], Value: fieldGroup(fields)}}
+	}
+	return fields
+}
+
+// mergeGroups 把同名分组合并到第一次出现的位置，使 With 与单次调用添加到同一分组的字段出现在同一个对象中
+func mergeGroups(fields []Field) []Field {
+	var idx map[string]int
+	var merged []Field
+	for i, f := range fields {
+		g, ok := f.Value.(fieldGroup)
+		if !ok {
+			if merged != nil {
+				merged = append(merged, f)
+			}
+			continue
+		}
+		if idx == nil {
+			idx = make(map[string]int)
+		}
+		j, seen := idx[f.Key]
+		if !seen {
+			if merged != nil {
+				idx[f.Key] = len(merged)
+				merged = append(merged, f)
+			} else {
+				idx[f.Key] = i
+			}
+			continue
+		}
+		if merged == nil {
+			merged = append([]Field(nil), fields[:i]...)
+		}
+		if prev, ok := merged[j].Value.(fieldGroup); ok {
+			merged[j].Value = append(prev[:len(prev):len(prev)], g...)
+		}
+	}
+	if merged == nil {
+		return fields
+	}
+	return merged
+}
+
+// flattenFields 把嵌套分组展开为以点号连接的 key，供 plain 与 logfmt 使用
+func flattenFields(fields []Field) []Field {
+	var flat []Field
+	for i, f := range fields {
+		g, ok := f.Value.(fieldGroup)
+		if !ok {
+			if flat != nil {
+				flat = append(flat, f)
+			}
+			continue
+		}
+		if flat == nil {
+			flat = append([]Field(nil), fields[:i]...)
+		}
+		for _, sub := range flattenFields(g) {
+			flat = append(flat, Field{Key: f.Key + "." + sub.Key, Value: sub.Value})
+		}
+	}
+	if flat == nil {
+		return fields
+	}
+	return flat
+}
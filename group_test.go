@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试 Group 在 JSON 中输出嵌套对象、在 plain 中展开为 name.key，嵌套分组可组合
+func TestGroup(t *testing.T) {
+	run := func(format Format) string {
+		log, buf := newBufferLogger(t, Config{
+			Synchronous:   true,
+			MinLevel:      INFO,
+			Format:        format,
+			MaskKeys:      []string{"token"},
+			IncludeCaller: new(bool),
+		})
+		http := log.With("svc", "api").Group("http").With("method", "GET")
+		http.Infow("done", "status", 200)
+		http.Group("req").Infow("nested", "id", 7, "token", "secret")
+		log.Group("empty").Info("no fields")
+		log.Close()
+		return buf.String()
+	}
+
+	lines := strings.Split(strings.TrimSpace(run(FormatJSON)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d JSON lines: %q", len(lines), lines)
+	}
+	wants := []string{
+		`"message":"done","svc":"api","http":{"method":"GET","status":200}}`,
+		`"message":"nested","svc":"api","http":{"method":"GET","req":{"id":7,"token":"***"}}}`,
+		`"message":"no fields"}`,
+	}
+	for i, want := range wants {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("JSON line %d = %s\nwant suffix %s", i, lines[i], want)
+		}
+	}
+
+	plain := run(FormatPlain)
+	for _, want := range []string{
+		"done svc=api http.method=GET http.status=200\n",
+		"nested svc=api http.method=GET http.req.id=7 http.req.token=***\n",
+		"no fields\n",
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("plain output missing %q:\n%s", want, plain)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GRPCLogger 把 Logger 适配为 gRPC 的 grpclog.LoggerV2 接口（按方法集匹配，不依赖 gRPC），
+// 通过 grpclog.SetLoggerV2(logger.NewGRPCLogger(log)) 让 gRPC 内部日志进入同一管道。
+// Info/Warning/Error 对应 INFO/WARN/ERROR；Fatal 以 FATAL 记录，关闭 Logger 后退出进程。
+type GRPCLogger struct {
+	log *Logger
+}
+
+// NewGRPCLogger 返回包装 l 的 gRPC 日志适配器
+func NewGRPCLogger(l *Logger) *GRPCLogger {
+	return &GRPCLogger{log: l}
+}
+
+// logf 在等级判断之后才格式化参数；depth 计入 GRPCLogger 的方法这一层
+func (g *GRPCLogger) logf(level Level, format func() string) {
+	if !g.log.enabled(level) {
+		return
+	}
+	g.log.log(2, level, format(), nil)
+}
+
+func sprintln(args []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+func (g *GRPCLogger) Info(args ...interface{}) {
+	g.logf(INFO, func() string { return fmt.Sprint(args...) })
+}
+func (g *GRPCLogger) Infoln(args ...interface{}) {
+	g.logf(INFO, func() string { return sprintln(args) })
+}
+func (g *GRPCLogger) Infof(format string, args ...interface{}) {
+	g.logf(INFO, func() string { return fmt.Sprintf(format, args...) })
+}
+
+func (g *GRPCLogger) Warning(args ...interface{}) {
+	g.logf(WARN, func() string { return fmt.Sprint(args...) })
+}
+func (g *GRPCLogger) Warningln(args ...interface{}) {
+	g.logf(WARN, func() string { return sprintln(args) })
+}
+func (g *GRPCLogger) Warningf(format string, args ...interface{}) {
+	g.logf(WARN, func() string { return fmt.Sprintf(format, args...) })
+}
+
+func (g *GRPCLogger) Error(args ...interface{}) {
+	g.logf(ERROR, func() string { return fmt.Sprint(args...) })
+}
+func (g *GRPCLogger) Errorln(args ...interface{}) {
+	g.logf(ERROR, func() string { return sprintln(args) })
+}
+func (g *GRPCLogger) Errorf(format string, args ...interface{}) {
+	g.logf(ERROR, func() string { return fmt.Sprintf(format, args...) })
+}
+
+func (g *GRPCLogger) Fatal(args ...interface{}) {
+	g.logf(FATAL, func() string { return fmt.Sprint(args...) })
+	g.fatal()
+}
+func (g *GRPCLogger) Fatalln(args ...interface{}) {
+	g.logf(FATAL, func() string { return sprintln(args) })
+	g.fatal()
+}
+func (g *GRPCLogger) Fatalf(format string, args ...interface{}) {
+	g.logf(FATAL, func() string { return fmt.Sprintf(format, args...) })
+	g.fatal()
+}
+
+// fatal 写完已入队的日志后退出，与 grpclog 的 Fatal 语义一致
+func (g *GRPCLogger) fatal() {
+	g.log.Close()
+	exit(1)
+}
+
+// V 报告 gRPC 详细级别 l 是否会被输出：0 对应 INFO，1 对应 DEBUG，2 及以上对应 TRACE
+func (g *GRPCLogger) V(l int) bool {
+	switch {
+	case l <= 0:
+		return g.log.enabled(INFO)
+	case l == 1:
+		return g.log.enabled(DEBUG)
+	default:
+		return g.log.enabled(TRACE)
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// grpcLoggerV2 与 google.golang.org/grpc/grpclog.LoggerV2 的方法集一致
+type grpcLoggerV2 interface {
+	Info(args ...interface{})
+	Infoln(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warning(args ...interface{})
+	Warningln(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorln(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalln(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	V(l int) bool
+}
+
+var _ grpcLoggerV2 = (*GRPCLogger)(nil)
+
+// 测试 gRPC 适配器各方法输出到对应等级，V 与 MinLevel 一致，caller 指向调用处
+func TestGRPCLogger(t *testing.T) {
+	log, capture := NewCapturing()
+	log.SetLevel(INFO)
+	var code int
+	exit = func(c int) { code = c }
+	defer func() { exit = os.Exit }()
+
+	g := NewGRPCLogger(log)
+	line := here() + 1
+	g.Info("info ", 1)
+	g.Infoln("info", 2)
+	g.Infof("info %d", 3)
+	g.Warning("warn")
+	g.Warningln("warn", "ln")
+	g.Warningf("warn %s", "f")
+	g.Error("error")
+	g.Errorln("error", "ln")
+	g.Errorf("error %d", 9)
+
+	want := []string{
+		"INFO info 1", "INFO info 2", "INFO info 3",
+		"WARN warn", "WARN warn ln", "WARN warn f",
+		"ERROR error", "ERROR error ln", "ERROR error 9",
+	}
+	recs := capture.Lines()
+	if len(recs) != len(want) {
+		t.Fatalf("got %d records; want %d", len(recs), len(want))
+	}
+	for i, rec := range recs {
+		if got := levelToStr(rec.Level) + " " + rec.Message; got != want[i] {
+			t.Errorf("record %d = %q; want %q", i, got, want[i])
+		}
+	}
+	if c := recs[0].Caller; !strings.HasPrefix(c, "grpc_test.go:"+strconv.Itoa(line)+" ") {
+		t.Errorf("caller = %q; want grpc_test.go:%d", c, line)
+	}
+
+	if !g.V(0) || g.V(1) || g.V(2) {
+		t.Errorf("V at INFO = %v,%v,%v; want true,false,false", g.V(0), g.V(1), g.V(2))
+	}
+	log.SetLevel(DEBUG)
+	if !g.V(1) || g.V(2) {
+		t.Errorf("V at DEBUG = %v,%v; want true,false", g.V(1), g.V(2))
+	}
+
+	g.Fatalf("fatal %d", 1)
+	if code != 1 {
+		t.Errorf("Fatalf exit code = %d; want 1", code)
+	}
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader 是 Middleware 读取和回写请求 ID 的头部
+const RequestIDHeader = "X-Request-ID"
+
+// Middleware 为每个请求派生一个带 request_id、method、path 字段的 Logger 并放入请求 ctx，
+// 处理函数中通过 FromContext(r.Context()) 取出，同一请求的日志即可按 request_id 关联。
+// 请求已带 X-Request-ID 时沿用该值，否则随机生成；响应中回写同一头部。
+func Middleware(l *Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			reqLog := l.With("request_id", id, "method", r.Method, "path", r.URL.Path)
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), reqLog)))
+		})
+	}
+}
+
+// newRequestID 生成 16 位十六进制的随机 ID
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func fieldValue(fields []Field, key string) interface{} {
+	for _, f := range fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// 测试 Middleware 注入的 Logger 使处理函数中的日志都带上同一个请求 ID
+func TestMiddlewareRequestID(t *testing.T) {
+	log, capture := NewCapturing()
+	handler := Middleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqLog := FromContext(r.Context())
+		reqLog.Info("handling")
+		reqLog.Infow("done", "status", 200)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	id := rec.Header().Get(RequestIDHeader)
+	if len(id) != 16 {
+		t.Fatalf("response %s = %q; want a generated 16-char ID", RequestIDHeader, id)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set(RequestIDHeader, "upstream-id")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	recs := capture.Lines()
+	if len(recs) != 4 {
+		t.Fatalf("got %d records; want 4", len(recs))
+	}
+	for i, want := range []struct{ id, method, path string }{
+		{id, "GET", "/orders/1"}, {id, "GET", "/orders/1"},
+		{"upstream-id", "POST", "/orders"}, {"upstream-id", "POST", "/orders"},
+	} {
+		f := recs[i].Fields
+		if fieldValue(f, "request_id") != want.id || fieldValue(f, "method") != want.method || fieldValue(f, "path") != want.path {
+			t.Errorf("record %d fields = %v; want request_id=%s method=%s path=%s", i, f, want.id, want.method, want.path)
+		}
+	}
+	if fieldValue(recs[1].Fields, "status") != 200 {
+		t.Errorf("record 1 fields = %v; want status=200 as well", recs[1].Fields)
+	}
+}
+
+// 测试 FromContext 取出 NewContext 放入的 Logger；没有时返回单例的情形见 logger_test.go，
+// 因为 GetLoggerInstance 只有第一次调用的配置生效，需在 TestLoggerBasic 初始化单例之后测试
+func TestFromContext(t *testing.T) {
+	log := NewNop()
+	if got := FromContext(NewContext(context.Background(), log)); got != log {
+		t.Errorf("FromContext did not return the stored logger")
+	}
+}
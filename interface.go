@@ -0,0 +1,30 @@
+package logger
+
+import "context"
+
+// Interface 是记录日志所需的方法集合，*Logger 实现了它。依赖日志的代码接收 Interface 而不是 *Logger，
+// 测试中即可换成 logmock.Logger 断言记录的内容，或用 NewNop() 丢弃全部日志。
+// 派生（With、Named）与配置、关闭等管理方法不在其中，仍由持有 *Logger 的一方调用。
+type Interface interface {
+	Trace(msg string)
+	Debug(msg string)
+	Info(msg string)
+	Warn(msg string)
+	Error(msg string)
+
+	Tracew(msg string, keysAndValues ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+
+	TraceCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+	DebugCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+	InfoCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+	WarnCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+	ErrorCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+
+	Enabled(level Level) bool
+}
+
+var _ Interface = (*Logger)(nil)
@@ -0,0 +1,39 @@
+package logger
+
+// levelGated 是 DebugField 添加的字段值：只有日志等级不高于 max 时才调用 fn 求值并输出
+type levelGated struct {
+	max Level
+	fn  func() interface{}
+}
+
+// DebugField 返回一个派生 Logger，只在它输出 DEBUG（及 TRACE）日志时调用 valueFunc 求值并附加 key 字段，
+// INFO 及以上的日志既不包含该字段也不调用 valueFunc，适合请求头全文等只在排查时需要的冗长内容。
+// valueFunc 在日志调用方的协程中执行，每条日志求值一次。
+func (l *Logger) DebugField(key string, valueFunc func() interface{}) *Logger {
+	if l.nop || valueFunc == nil {
+		return l
+	}
+	child := l.withFields([]Field{{Key: key, Value: levelGated{max: DEBUG, fn: valueFunc}}})
+	child.gated = true
+	return child
+}
+
+// resolveGated 返回按 level 求值或去掉 levelGated 字段后的新切片，分组中的字段同样处理，去掉后为空的分组一并去掉
+func resolveGated(fields []Field, level Level) []Field {
+	out := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		switch v := f.Value.(type) {
+		case levelGated:
+			if level <= v.max {
+				out = append(out, Field{Key: f.Key, Value: v.fn()})
+			}
+		case fieldGroup:
+			if sub := resolveGated(v, level); len(sub) > 0 {
+				out = append(out, Field{Key: f.Key, Value: fieldGroup(sub)})
+			}
+		default:
+			out = append(out, f)
+		}
+	}
+	return out
+}
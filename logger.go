@@ -1,14 +1,21 @@
 package logger
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
@@ -16,14 +23,21 @@ import (
 type Level int
 
 const (
-	DEBUG Level = iota
+	TRACE Level = iota
+	DEBUG
 	INFO
 	WARN
 	ERROR
+	FATAL // 仅由 Fatal/FatalCode 使用，写出后进程退出
 )
 
+// numLevels 为等级总数，用于按等级计数
+const numLevels = int(FATAL) + 1
+
 func levelToStr(l Level) string {
 	switch l {
+	case TRACE:
+		return "TRACE"
 	case DEBUG:
 		return "DEBUG"
 	case INFO:
@@ -32,249 +46,1653 @@ func levelToStr(l Level) string {
 		return "ERROR"
 	case WARN:
 		return "WARN"
+	case FATAL:
+		return "FATAL"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+// levelOff 高于所有等级，NewNop 的 GetLevel 返回该值表示不输出任何日志
+const levelOff Level = 1<<31 - 1
+
+func (l Level) String() string { return levelToStr(l) }
+
+// ParseLevel 按名称解析日志等级，不区分大小写
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TRACE":
+		return TRACE, nil
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN", "WARNING":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	case "FATAL":
+		return FATAL, nil
+	default:
+		return 0, fmt.Errorf("logger: unknown level %q", s)
+	}
+}
+
+// MarshalText 按名称序列化等级，避免枚举数值变化影响已持久化的配置
+func (l Level) MarshalText() ([]byte, error) {
+	if levelToStr(l) == "UNKNOWN" {
+		return nil, fmt.Errorf("logger: invalid level %d", int(l))
+	}
+	return []byte(levelToStr(l)), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
 type Format int
 
 const (
 	FormatPlain Format = iota
 	FormatJSON
+	FormatLogfmt
+	FormatECS // Elastic Common Schema 结构的 JSON，见 ecs.go
 )
 
+// numFormats 为内置格式总数
+const numFormats = int(FormatECS) + 1
+
 type Config struct {
-	MinLevel      Level
-	Format        Format
-	Targets       OutputTarget
-	LogPath       string
-	AllowedPrefix []string // 白名单包名前缀
+	MinLevel                  Level
+	Format                    Format
+	Targets                   OutputTarget
+	LogPath                   string
+	AllowedPrefix             []string               // 白名单包名前缀
+	LevelColors               map[Level]string       // 按等级覆盖控制台颜色（ANSI 转义序列），未设置的等级使用默认颜色
+	ErrorsToStderr            bool                   // 控制台输出时 WARN 及以上写入 stderr，其余写入 stdout
+	JSONKeys                  JSONKeys               // 自定义 JSON 格式的标准字段名
+	LogConfigOnStart          bool                   // 启动后先输出一条 INFO 日志，汇总实际生效的配置
+	RotateDaily               bool                   // 按日期切换日志文件，例如 logs/app-2024-01-15.log
+	MaskKeys                  []string               // 结构化字段的 key 包含其中任一项（不区分大小写）时，值输出为 ***
+	FileRotation              RotateConfig           // 主日志文件的轮转设置
+	AllowedRotation           RotateConfig           // 白名单日志文件的轮转设置
+	Overflow                  OverflowPolicy         // 通道已满时的处理方式，默认阻塞
+	DropReportInterval        time.Duration          // OverflowDrop 时汇报丢弃条数的间隔，默认 10s
+	IncludeCaller             *bool                  // 是否记录调用位置，默认 true；关闭后跳过栈回溯且输出中不含 caller
+	SplitCaller               bool                   // JSON 格式中把 caller 拆成 file、line、func 三个字段
+	SyncOnError               bool                   // 写出 ERROR 后立即把日志文件同步到磁盘
+	PrefixLevels              map[string]Level       // 按调用函数的完整名称（含导入路径）前缀单独设置最低等级，最长前缀优先，未匹配时使用 MinLevel
+	Formatter                 func(LogRecord) string // 设置后代替内置格式生成每条日志（需自带换行），输出原样写入除 JSONLPath 外的所有目标，控制台不再着色
+	MaxMessageBytes           int                    // 消息超过该字节数时截断并附加 ...[truncated N bytes]，0 表示不限制
+	EventLogSource            string                 // OutputEventLog 的事件来源名称，默认为可执行文件名
+	FlushInterval             time.Duration          // 大于 0 时按该间隔把有新写入的日志文件同步到磁盘，0 表示不定时同步
+	StructuredPanic           bool                   // JSON 格式下 panic 日志改为 panic 与 stack（帧数组）字段，plain 格式仍输出文本栈
+	ConsoleFormat             *Format                // 控制台输出使用的格式，nil 时使用 Format
+	FileFormat                *Format                // 日志文件（含白名单文件）使用的格式，nil 时使用 Format
+	Sampling                  *SamplingConfig        // 对 DEBUG/INFO 按消息采样，nil 表示不采样
+	TimePrecision             TimePrecision          // 时间戳精度；设置后 JSON 输出该精度的整数纪元时间，plain 追加小数秒
+	ConsoleWriter             io.Writer              // 控制台输出（含彩色 panic 输出）的目标，默认 os.Stdout/os.Stderr；设置后 ErrorsToStderr 不再生效，需并发安全
+	SanitizeNewlines          bool                   // plain 格式中转义消息、caller、组件名和字段里的换行与控制字符，保证一次调用只产生一行
+	OnRotate                  func(oldPath string)   // 主日志文件轮转（按大小或按日期切换）后在独立协程中调用，参数为轮转出去的旧文件路径
+	HeartbeatInterval         time.Duration          // 非 0 时按该间隔输出一条 INFO 心跳日志，包含协程数、堆内存与 GC 次数
+	AllowedMinLevel           Level                  // 写入白名单日志文件的最低等级，默认 TRACE（不额外过滤）
+	BufferSize                int                    // 日志通道容量，默认 1000，仅在创建 Logger 时生效
+	EnqueueTimeout            time.Duration          // OverflowBlock 策略下通道已满时最多等待的时长，到期仍无空位则丢弃并计数；0 表示一直等待
+	OverflowBufferSize        int                    // 通道已满时额外缓存的日志条数，0 表示不启用；队列也满时才按 Overflow 处理
+	JSONLPath                 string                 // 非空时另外把每条日志（WriteRaw 的行除外）以 JSON 行写入该文件，不受 Format 与 Formatter 影响，轮转设置同 FileRotation
+	OSLogSubsystem            string                 // OutputOSLog 的 subsystem，默认为可执行文件名
+	PanicStackSize            int                    // 文本 panic 栈缓冲区的初始字节数，默认 64KB；栈更深时自动加倍直到完整（最多 16MB）
+	AutoDebugOnErrorBurst     *ErrorBurstConfig      // 短时间内错误突增时临时切换到 DEBUG，nil 表示不启用
+	OnErrorAlert              func(LogRecord)        // ERROR 及以上的日志写出后调用，同一签名在 AlertCooldown 内最多一次，适合接入告警；在后台写出协程中调用，不应阻塞
+	AlertCooldown             time.Duration          // OnErrorAlert 同一签名两次调用的最短间隔，默认 1 分钟
+	AlertSignature            func(LogRecord) string // 决定哪些错误视为同一种，默认为 DefaultAlertSignature（调用位置加消息）
+	SummaryOnClose            bool                   // Close 写完全部日志后、关闭写入器前输出一条按等级汇总已写出条数的 INFO，适合批处理任务
+	DeadlineWarnThreshold     time.Duration          // 大于 0 时，InfoCtx 等方法在 ctx 截止时间已过或剩余不足该值时附加 ctx_deadline_remaining 字段
+	DeadlineBumpToWarn        bool                   // 配合 DeadlineWarnThreshold，把临近或超过截止时间的 TRACE/DEBUG/INFO 日志提升为 WARN
+	IncludeSequence           bool                   // 为每条日志在入队时分配单调递增的序号（从 1 开始），JSON 与 logfmt 中为 seq 字段，plain 中为行首的 #序号；序号出现空缺说明有日志被丢弃或采样
+	CallerPathStyle           CallerPathStyle        // plain 格式中 caller 的文件部分：CallerShort 文件名（默认）、CallerRelative 相对 CallerRoot 的路径、CallerAbsolute 完整路径，便于 IDE 与终端点击跳转
+	CallerRoot                string                 // CallerRelative 的项目根目录，默认为进程的工作目录
+	Hooks                     []Hook                 // 每条日志写出后依次调用，参数为脱敏后的 LogRecord；在后台写出协程中执行，不应阻塞
+	LineSeparator             string                 // 内置格式追加在每条日志末尾的分隔符，默认 "\n"，例如 Windows 下可设为 "\r\n"；Formatter 的输出原样使用
+	OmitLineSeparator         OutputTarget           // 这些输出目标不追加 LineSeparator，例如按帧传输、自行分隔记录的下游；OutputFile 同时作用于白名单文件与 JSONLPath
+	CompactLevel              bool                   // plain 格式中等级只输出单个字母（T/D/I/W/E/F），不带方括号；JSON 与 logfmt 不受影响
+	FallbackWriter            io.Writer              // 通道已满时不再阻塞或丢弃，改为在调用方协程中把日志直接写入该写入器（如 os.Stderr）并单独计数，与通道中的日志不保证顺序
+	SeverityMap               func(int) Level        // LogAt 把外部数值严重级别换算为 Level 的函数，nil 时使用 SyslogSeverity
+	CorrelationIDFunc         func() string          // NewOperation 生成关联 ID 的函数，nil 时使用随机 UUID
+	DecompressExistingBackups bool                   // 创建时在后台把日志目录中已有的 .gz 备份解压回普通文件（一次性迁移用），通常与 Compress=false 一起使用
+	Synchronous               bool                   // 在调用方协程中直接格式化并写出，不经过通道与后台协程；仅在创建时生效，FlushInterval、HeartbeatInterval 不再生效
+	AllowedMatch              MatchField             // AllowedPrefix 与调用位置的哪一部分匹配，默认 MatchFull
+	AllowedToConsole          bool                   // 白名单日志另外以 [ALLOWED] 行首标记输出到控制台；控制台已是输出目标时只加标记，不重复输出
+}
+
+// MatchField 决定 AllowedPrefix 中的每一项与调用位置的哪一部分匹配
+type MatchField int
+
+const (
+	MatchFull MatchField = iota // 包含于 "file.go:42 pkg.Func" 形式的完整 caller 中
+	MatchFile                   // 文件名（不含目录）以该项开头
+	MatchFunc                   // 函数名（如 server.(*API).ServeHTTP）以该项开头，或最后一段（ServeHTTP）与该项相同
+)
+
+func (f MatchField) MarshalText() ([]byte, error) {
+	switch f {
+	case MatchFull:
+		return []byte("full"), nil
+	case MatchFile:
+		return []byte("file"), nil
+	case MatchFunc:
+		return []byte("func"), nil
+	default:
+		return nil, fmt.Errorf("logger: invalid match field %d", int(f))
+	}
+}
+
+func (f *MatchField) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "full":
+		*f = MatchFull
+	case "file":
+		*f = MatchFile
+	case "func":
+		*f = MatchFunc
+	default:
+		return fmt.Errorf("logger: unknown match field %q", text)
+	}
+	return nil
+}
+
+// OverflowPolicy 决定日志通道已满时 log() 的行为
+type OverflowPolicy int
+
+const (
+	OverflowBlock OverflowPolicy = iota // 阻塞调用方直到有空位
+	OverflowDrop                        // 直接丢弃并计数，定期输出一条 WARN 汇总
+)
+
+func (p OverflowPolicy) MarshalText() ([]byte, error) {
+	switch p {
+	case OverflowBlock:
+		return []byte("block"), nil
+	case OverflowDrop:
+		return []byte("drop"), nil
+	default:
+		return nil, fmt.Errorf("logger: invalid overflow policy %d", int(p))
+	}
+}
+
+func (p *OverflowPolicy) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "block":
+		*p = OverflowBlock
+	case "drop":
+		*p = OverflowDrop
+	default:
+		return fmt.Errorf("logger: unknown overflow policy %q", text)
+	}
+	return nil
+}
+
+const defaultDropReportInterval = 10 * time.Second
+
+// RotateConfig 是单个日志文件的轮转设置，零值字段使用默认值
+type RotateConfig struct {
+	MaxSize    int   // 单个文件最大 MB，默认 10
+	MaxBackups int   // 保留的旧文件数，默认 5
+	MaxAge     int   // 旧文件保留天数，默认 7
+	Compress   *bool // 是否 gzip 压缩旧文件，默认 true
+}
+
+func (r RotateConfig) withDefaults() RotateConfig {
+	if r.MaxSize == 0 {
+		r.MaxSize = 10
+	}
+	if r.MaxBackups == 0 {
+		r.MaxBackups = 5
+	}
+	if r.MaxAge == 0 {
+		r.MaxAge = 7
+	}
+	if r.Compress == nil {
+		compress := true
+		r.Compress = &compress
+	}
+	return r
+}
+
+func (r RotateConfig) equal(o RotateConfig) bool {
+	r, o = r.withDefaults(), o.withDefaults()
+	return r.MaxSize == o.MaxSize && r.MaxBackups == o.MaxBackups &&
+		r.MaxAge == o.MaxAge && *r.Compress == *o.Compress
+}
+
+func (f Format) String() string {
+	switch f {
+	case FormatPlain:
+		return "plain"
+	case FormatJSON:
+		return "json"
+	case FormatLogfmt:
+		return "logfmt"
+	case FormatECS:
+		return "ecs"
+	default:
+		return "unknown"
+	}
+}
+
+func (f Format) MarshalText() ([]byte, error) {
+	if f.String() == "unknown" {
+		return nil, fmt.Errorf("logger: invalid format %d", int(f))
+	}
+	return []byte(f.String()), nil
 }
 
+func (f *Format) UnmarshalText(text []byte) error {
+	for _, candidate := range []Format{FormatPlain, FormatJSON, FormatLogfmt, FormatECS} {
+		if strings.EqualFold(string(text), candidate.String()) {
+			*f = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("logger: unknown format %q", text)
+}
+
+// OutputTarget 是输出目标的位掩码，可用 | 组合。各取值固定为 2 的幂，
+// 新增目标依次使用下一位，已有取值不会改变。
 type OutputTarget int
 
 const (
-	OutputNone    OutputTarget = 0
-	OutputConsole OutputTarget = 1 << iota
-	OutputFile
+	OutputNone     OutputTarget = 0
+	OutputConsole  OutputTarget = 1 << 0 // 1
+	OutputFile     OutputTarget = 1 << 1 // 2
+	OutputEventLog OutputTarget = 1 << 2 // 4，Windows 事件日志，其他平台上启用会返回 ErrEventLogUnsupported
+	OutputOSLog    OutputTarget = 1 << 3 // 8，macOS 统一日志（Console.app），其他平台或禁用 cgo 时返回 ErrOSLogUnsupported
 )
 
+// outputTargetNames 是输出目标与配置文件中名称的对应关系
+var outputTargetNames = []struct {
+	target OutputTarget
+	name   string
+}{
+	{OutputConsole, "console"},
+	{OutputFile, "file"},
+	{OutputEventLog, "eventlog"},
+	{OutputOSLog, "oslog"},
+}
+
+func (t OutputTarget) names() []string {
+	names := []string{}
+	for _, n := range outputTargetNames {
+		if t&n.target != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+func (t OutputTarget) String() string {
+	names := t.names()
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// MarshalJSON 将输出目标序列化为名称列表，例如 ["console","file"]；OutputNone 为 []
+func (t OutputTarget) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.names())
+}
+
+// UnmarshalJSON 解析名称列表并按位或组合，未知名称返回错误
+func (t *OutputTarget) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("logger: targets must be a list of names: %w", err)
+	}
+	var target OutputTarget
+	for _, name := range names {
+		found := false
+		for _, n := range outputTargetNames {
+			if strings.EqualFold(name, n.name) {
+				target |= n.target
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("logger: unknown output target %q", name)
+		}
+	}
+	*t = target
+	return nil
+}
+
 type logMsg struct {
-	Level   Level
-	Message string
-	Time    time.Time
-	Caller  string
+	Level     Level
+	Message   string
+	Time      time.Time
+	Caller    string
+	Fields    []Field
+	Component string
+	File      string // 调用位置的文件名（不含目录）
+	Path      string // 调用位置文件的完整路径，供 CallerPathStyle 使用
+	Line      int
+	Func      string     // 调用位置的函数名（含包名）
+	Raw       bool       // WriteRaw 写入的预格式化行，Message 原样输出
+	Seq       uint64     // IncludeSequence 开启时入队时分配的序号，0 表示没有序号
+	Formats   *formatSet // Clone 派生的 Logger 使用的输出格式，nil 表示使用配置中的
+
+	Targets         OutputTarget // OverrideTargets 为 true 时代替 config.Targets，由 To 设置
+	OverrideTargets bool
 }
 
+// Logger 是对外的日志句柄，Named 等派生出的 Logger 共享同一个 core
 type Logger struct {
-	logChan         chan logMsg
-	quit            chan struct{}
-	config          Config
-	fileLogger      *lumberjack.Logger
-	allowFileLogger *lumberjack.Logger
+	*core
+	nop       bool          // NewNop 创建的空日志器，所有方法直接返回
+	component string        // Named 设置的子系统名称
+	fields    []Field       // With 累积的字段，只读，派生时复制
+	groups    []string      // Group 设置的分组路径，之后添加的字段嵌套在其下
+	gated     bool          // fields 中含 DebugField 添加的按等级求值的字段
+	targets   *OutputTarget // To 设置的输出目标，nil 表示使用配置中的 Targets
+	clone     *cloneConfig  // Clone 设置的等级与格式，nil 表示使用 core 中的
 }
 
+// levelState 是最低输出等级以及 BoostLevel、PushLevel 的状态
+type levelState struct {
+	minLevel   atomic.Int32 // config.MinLevel 的原子副本，供 log() 热路径无锁读取
+	boostMu    sync.Mutex   // 保护 boostTimer、boostBase 与 levelStack
+	boostTimer *time.Timer  // 当前生效的 BoostLevel 定时器，没有临时提升时为 nil
+	boostBase  Level        // BoostLevel 到期后恢复的等级
+	levelStack []*levelPush // PushLevel 尚未恢复的层，见 pushlevel.go
+}
+
+// core 持有通道、写入器和后台协程，由同源的所有 Logger 共享
+type core struct {
+	logChan           chan logMsg
+	quit              chan struct{}
+	done              chan struct{} // start() 退出（排空完成）后关闭
+	closeOnce         sync.Once
+	pending           atomic.Int64    // 已入队但尚未写出的日志数
+	ctrl              chan controlReq // Reconfigure / Flush 请求，由 start() 串行处理
+	mu                sync.RWMutex    // 保护 config 与写入器；修改时加写锁，start() 之外的协程读取时加读锁
+	config            Config
+	levelState        // 最低等级及其临时调整，克隆另有自己的一份，见 Clone
+	stdout            io.Writer
+	stderr            io.Writer
+	fileLogger        io.WriteCloser
+	allowFileLogger   io.WriteCloser
+	jsonlLogger       io.WriteCloser               // JSONLPath 的写入器，未配置时为 nil
+	capture           *Capture                     // NewCapturing 创建的 Logger 会把每条记录交给它
+	written           [numLevels]atomic.Uint64     // 按等级统计已写出的日志数
+	dropped           atomic.Uint64                // 被丢弃的日志数
+	droppedInterval   atomic.Uint64                // 上次汇报之后新增的丢弃数
+	dropOnFull        atomic.Bool                  // config.Overflow == OverflowDrop 的原子副本
+	enqueueTimeout    atomic.Int64                 // config.EnqueueTimeout 的原子副本
+	noCaller          atomic.Bool                  // IncludeCaller 为 false 的原子副本
+	includeSeq        atomic.Bool                  // config.IncludeSequence 的原子副本
+	deadlineThreshold atomic.Int64                 // config.DeadlineWarnThreshold 的原子副本
+	deadlineBump      atomic.Bool                  // config.DeadlineBumpToWarn 的原子副本
+	seq               atomic.Uint64                // 最近分配的序号
+	prefixLevels      atomic.Pointer[prefixLevels] // config.PrefixLevels 的预处理副本，未配置时为 nil
+	maxMessageBytes   atomic.Int64                 // config.MaxMessageBytes 的原子副本
+	eventLog          eventSink                    // OutputEventLog 的事件日志句柄
+	osLog             osLogSink                    // OutputOSLog 的 os_log 句柄
+	unsynced          bool                         // 上次定时同步之后是否有新的文件写入，仅由 start() 访问
+	fileErrors        int                          // fileLogger 连续写入失败的次数，仅由 start() 访问
+	failedFile        io.WriteCloser               // 被判定为不可写的 fileLogger，见 filefail.go；仅由 start() 访问
+	sampler           *sampler                     // config.Sampling 的运行状态，仅由 start() 访问
+	burst             *burstDetector               // config.AutoDebugOnErrorBurst 的运行状态，仅由 start() 访问
+	alerts            alertState                   // OnErrorAlert 的去重状态，仅由 start() 访问
+	sampled           atomic.Uint64                // 被采样丢弃的日志数
+	fallback          atomic.Uint64                // 通道已满时直接写入 FallbackWriter 的日志数
+	fallbackMu        sync.Mutex                   // 串行化对 FallbackWriter 的写入
+	subsMu            sync.Mutex                   // 保护 subs 与 subsClosed
+	subs              map[*subscriber]struct{}     // Subscribe 注册的订阅者，见 subscribe.go
+	subsClosed        bool                         // Logger 已关闭，不再接受订阅
+	subCount          atomic.Int32                 // len(subs) 的原子副本，write() 据此跳过没有订阅者时的开销
+	subDropped        atomic.Uint64                // 因订阅通道已满而丢弃的记录数
+	latency           latencyTracker               // 最近日志从调用到写出的排队耗时，见 stats.go
+	overflowMu        sync.Mutex                   // 保护以下溢出队列状态
+	overflow          []logMsg                     // 通道已满时的第二级缓冲，见 enqueueOverflow
+	overflowSpace     chan struct{}                // 每次取空队列时关闭并替换，唤醒等待空位的调用方
+	overflowReady     chan struct{}                // 队列中追加日志后通知 start()，容量 1
+	overflowing       atomic.Bool                  // 溢出队列非空；为 true 时新日志不再直接进入通道
+	maxOverflow       atomic.Int64                 // config.OverflowBufferSize 的原子副本
+	closed            atomic.Bool                  // Close 已调用，之后的日志直接计为丢弃
+	inflight          atomic.Int64                 // 正在入队的调用数，关闭时 start() 等它归零后才退出
+	inflightIdle      chan struct{}                // 关闭后 inflight 归零时通知 start()，容量 1
+	closeDeadline     <-chan struct{}              // CloseContext 的 ctx.Done()，在 close(quit) 前设置；start() 等待在途入队不超过它
+	onceKeys          sync.Map                     // WarnOnce 已输出过的 key
+	synchronous       bool                         // config.Synchronous，创建后不变
+	syncMu            sync.Mutex                   // 同步模式下串行化写入、控制请求与关闭，见 synchronous.go
+}
+
+// controlReq 由 start() 在写完已入队日志后处理；cfg 为 nil 时仅作为 Flush 的屏障
+type controlReq struct {
+	cfg      *Config
+	warnings []string
+	reopen   bool                    // 重新打开文件写入器，结果写入 err
+	sync     bool                    // 强制同步日志文件，包括绕过通道直接写入的 panic 日志
+	allowed  func([]string) []string // 修改 AllowedPrefix，见 allowed.go
+	err      *error                  // 由 control 设置，start() 在关闭 done 之前写入
+	done     chan struct{}
+}
+
+// ErrClosed 表示 Logger 已关闭
+var ErrClosed = errors.New("logger: closed")
+
+// CloseError 表示 CloseContext 在排空完成前超时，Unflushed 为尚未写出的日志数
+type CloseError struct {
+	Unflushed int
+	Err       error
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("logger: close: %d messages unflushed: %v", e.Unflushed, e.Err)
+}
+
+func (e *CloseError) Unwrap() error { return e.Err }
+
+// DefaultLogPath 是启用文件输出但未配置 LogPath 时使用的日志文件路径
+const DefaultLogPath = "logs/log.json"
+
 var (
 	instance *Logger
+	initErr  error
 	once     sync.Once
-	cfg      = Config{
+	cfg      = DefaultConfig()
+)
+
+// DefaultConfig 返回 GetLoggerInstance 未传配置时使用的默认配置
+func DefaultConfig() Config {
+	return Config{
 		MinLevel:      INFO,
 		Format:        FormatPlain,
 		Targets:       OutputConsole,
-		LogPath:       "logs/log.json",
+		LogPath:       DefaultLogPath,
 		AllowedPrefix: []string{},
 	}
-)
+}
 
 func GetLoggerInstance(cfgs ...Config) *Logger {
 	once.Do(func() {
 		if len(cfgs) > 0 {
 			cfg = cfgs[0]
 		}
+		instance, initErr = newLogger(cfg)
+		if initErr != nil {
+			instance.consoleWarn(initErr.Error())
+		}
+	})
+	return instance
+}
+
+// InitError 返回全局单例初始化时遇到的错误，没有错误或尚未初始化时返回 nil
+func InitError() error {
+	return initErr
+}
+
+// New 按配置创建一个独立的 Logger（不影响全局单例）。
+// 初始化错误只会作为 WARN 输出到控制台，需要处理错误时使用 NewWithError。
+func New(cfg Config) *Logger {
+	l, err := newLogger(cfg)
+	if err != nil {
+		l.consoleWarn(err.Error())
+	}
+	return l
+}
+
+// NewWithError 与 New 相同，但会返回目录创建、日志文件打开和配置校验中的错误，
+// 出错时不会返回 Logger。
+func NewWithError(cfg Config) (*Logger, error) {
+	l, err := newLogger(cfg)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// newLogger 总会返回一个可用的 Logger，初始化中遇到的错误一并返回
+func newLogger(cfg Config) (*Logger, error) {
+	cfg, warnings := validateConfig(cfg)
+	err := validatePaths(cfg)
+	if err == nil {
+		err = prepareDirs(cfg)
+	}
+
+	l := &Logger{core: &core{
+		sampler:       newSampler(cfg.Sampling),
+		burst:         newBurstDetector(cfg.AutoDebugOnErrorBurst),
+		logChan:       make(chan logMsg, bufferSize(cfg)),
+		overflowSpace: make(chan struct{}),
+		overflowReady: make(chan struct{}, 1),
+		inflightIdle:  make(chan struct{}, 1),
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+		ctrl:          make(chan controlReq),
+		config:        cfg,
+		stdout:        os.Stdout,
+		stderr:        os.Stderr,
+		synchronous:   cfg.Synchronous,
+	}}
+	l.storeHotConfig(cfg)
+
+	if cfg.Targets&OutputFile != 0 {
+		l.fileLogger = newMainWriter(cfg)
+	}
+	if len(cfg.AllowedPrefix) > 0 {
+		l.allowFileLogger = newFileWriter(allowedLogPath, cfg.AllowedRotation)
+	}
+	if cfg.JSONLPath != "" {
+		l.jsonlLogger = newFileWriter(cfg.JSONLPath, cfg.FileRotation)
+	}
+	if cfg.Targets&OutputEventLog != 0 {
+		sink, openErr := openEventLog(eventLogSource(cfg))
+		if openErr != nil && err == nil {
+			err = openErr
+		}
+		l.eventLog = sink
+	}
+	if cfg.Targets&OutputOSLog != 0 {
+		sink, openErr := openOSLog(osLogSubsystem(cfg))
+		if openErr != nil && err == nil {
+			err = openErr
+		}
+		l.osLog = sink
+	}
+
+	for _, w := range warnings {
+		l.consoleWarn(w)
+	}
+
+	if !l.synchronous {
+		go l.start()
+	}
+	if cfg.LogConfigOnStart {
+		l.logConfigBanner()
+	}
+	if cfg.DecompressExistingBackups {
+		go l.decompressBackups(backupPaths(cfg))
+	}
+	return l, err
+}
+
+// logConfigBanner 不受 MinLevel 限制，输出一条汇总生效配置的 INFO 日志
+func (l *Logger) logConfigBanner() {
+	cfg := l.config
+	fields := []Field{
+		{Key: "min_level", Value: levelToStr(cfg.MinLevel)},
+		{Key: "format", Value: cfg.Format.String()},
+		{Key: "targets", Value: cfg.Targets.String()},
+	}
+	if cfg.Targets&OutputFile != 0 {
+		fields = append(fields, Field{Key: "log_path", Value: cfg.LogPath})
+	}
+	if len(cfg.AllowedPrefix) > 0 {
+		fields = append(fields,
+			Field{Key: "allowed_prefix", Value: strings.Join(cfg.AllowedPrefix, ",")},
+			Field{Key: "allowed_path", Value: allowedLogPath},
+		)
+	}
+	l.enqueue(logMsg{
+		Level:   INFO,
+		Message: "logger started",
+		Time:    time.Now(),
+		Caller:  "logger",
+		Fields:  fields,
+	})
+}
+
+const allowedLogPath = "logs_allowed/allowed.log"
 
-		if cfg.Targets&OutputFile == 1 {
-			logDir := filepath.Dir(cfg.LogPath)
-			if logDir != "" {
-				_ = os.MkdirAll(logDir, 0755)
+// allowedConsoleMarker 是 AllowedToConsole 时白名单日志在控制台的行首标记
+const allowedConsoleMarker = "[ALLOWED] "
+
+// prepareDirs 创建日志目录，并确认日志文件可以打开写入
+func prepareDirs(cfg Config) error {
+	if cfg.Targets&OutputFile != 0 {
+		logDir := filepath.Dir(cfg.LogPath)
+		if logDir != "" {
+			if err := os.MkdirAll(logDir, 0755); err != nil {
+				return fmt.Errorf("logger: create log dir: %w", err)
+			}
+		}
+		if !cfg.RotateDaily {
+			if err := checkWritable(cfg.LogPath); err != nil {
+				return err
 			}
 		}
+	}
 
-		// 如果配置了白名单输出，创建 logs_allowed/allowed.log
-		if len(cfg.AllowedPrefix) > 0 {
-			_ = os.MkdirAll("logs_allowed", 0755)
+	if cfg.JSONLPath != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.JSONLPath), 0755); err != nil {
+			return fmt.Errorf("logger: create jsonl dir: %w", err)
+		}
+		if err := checkWritable(cfg.JSONLPath); err != nil {
+			return err
 		}
+	}
 
-		instance = &Logger{
-			logChan: make(chan logMsg, 1000),
-			quit:    make(chan struct{}),
-			config:  cfg,
+	// 如果配置了白名单输出，创建 logs_allowed/allowed.log
+	if len(cfg.AllowedPrefix) > 0 {
+		if err := os.MkdirAll("logs_allowed", 0755); err != nil {
+			return fmt.Errorf("logger: create allowed log dir: %w", err)
 		}
+	}
+	return nil
+}
+
+// checkWritable 以追加方式打开一次文件，提前暴露权限等问题（lumberjack 要到首次写入才打开）
+func checkWritable(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("logger: open log file: %w", err)
+	}
+	return f.Close()
+}
+
+// newMainWriter 创建主日志文件的写入器
+func newMainWriter(cfg Config) io.WriteCloser {
+	if cfg.RotateDaily {
+		return newDailyWriter(cfg.LogPath, cfg.FileRotation, cfg.OnRotate)
+	}
+	return newRotatingWriter(cfg.LogPath, cfg.FileRotation, cfg.OnRotate)
+}
+
+func newFileWriter(path string, rotation RotateConfig) io.WriteCloser {
+	rotation = rotation.withDefaults()
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    rotation.MaxSize,
+		MaxBackups: rotation.MaxBackups,
+		MaxAge:     rotation.MaxAge,
+		Compress:   *rotation.Compress,
+	}
+}
+
+// syncWriter 把写入器中的数据同步到磁盘。lumberjack 不暴露文件句柄，
+// 这里另开一个句柄 fsync，同一文件的脏页会一并落盘。
+func syncWriter(w io.Writer) error {
+	switch w := w.(type) {
+	case *rotateNotifier:
+		return syncWriter(w.Logger)
+	case interface{ Sync() error }:
+		return w.Sync()
+	case *lumberjack.Logger:
+		f, err := os.OpenFile(w.Filename, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return f.Sync()
+	}
+	return nil
+}
+
+// Reconfigure 在运行时替换配置（格式、输出目标、写入器等）。
+// 调用前已入队的日志按旧配置写出，之后的日志使用新配置。
+func (l *Logger) Reconfigure(cfg Config) error {
+	if l.nop {
+		return nil
+	}
+	cfg, warnings := validateConfig(cfg)
+	if err := validatePaths(cfg); err != nil {
+		return err
+	}
+	if err := prepareDirs(cfg); err != nil {
+		return err
+	}
+	cfg.Synchronous = l.synchronous
+	l.core.cancel()
+	return l.control(controlReq{cfg: &cfg, warnings: warnings})
+}
+
+// Flush 阻塞直到调用前已入队的日志全部写出
+func (l *Logger) Flush() {
+	if l.nop {
+		return
+	}
+	if l.control(controlReq{}) == ErrClosed {
+		// 已关闭时等待排空结束即可
+		<-l.done
+	}
+}
+
+func (l *Logger) control(req controlReq) error {
+	var err error
+	req.err = &err
+	if l.synchronous {
+		l.controlSync(req)
+		return err
+	}
+	req.done = make(chan struct{})
+	select {
+	case l.ctrl <- req:
+	case <-l.quit:
+		return ErrClosed
+	}
+	<-req.done
+	return err
+}
+
+// Reopen 在写完已入队的日志后关闭日志文件，下次写入时在配置的路径重新创建，
+// 配合 logrotate 等外部工具使用：它们移走旧文件后调用 Reopen，新日志即写入新文件。
+func (l *Logger) Reopen() error {
+	if l.nop {
+		return nil
+	}
+	return l.control(controlReq{reopen: true})
+}
+
+// reopenFiles 只在 start() 中调用。lumberjack 与 dailyWriter 关闭后会在下次写入时重新打开文件，
+// 这里提前创建目录并检查可写，让路径问题在 Reopen 返回时暴露。
+func (l *Logger) reopenFiles() error {
+	l.failedFile, l.fileErrors = nil, 0
+	if l.fileLogger != nil {
+		_ = l.fileLogger.Close()
+	}
+	if l.allowFileLogger != nil {
+		_ = l.allowFileLogger.Close()
+	}
+	if l.jsonlLogger != nil {
+		_ = l.jsonlLogger.Close()
+	}
+	return prepareDirs(l.config)
+}
+
+// storeHotConfig 更新调用方协程会读取的配置副本
+func (l *Logger) storeHotConfig(cfg Config) {
+	l.minLevel.Store(int32(cfg.MinLevel))
+	l.dropOnFull.Store(cfg.Overflow == OverflowDrop)
+	l.enqueueTimeout.Store(int64(cfg.EnqueueTimeout))
+	l.noCaller.Store(cfg.IncludeCaller != nil && !*cfg.IncludeCaller)
+	l.includeSeq.Store(cfg.IncludeSequence)
+	l.deadlineThreshold.Store(int64(cfg.DeadlineWarnThreshold))
+	l.deadlineBump.Store(cfg.DeadlineBumpToWarn)
+	l.prefixLevels.Store(newPrefixLevels(cfg.PrefixLevels))
+	l.maxMessageBytes.Store(int64(cfg.MaxMessageBytes))
+	l.maxOverflow.Store(int64(cfg.OverflowBufferSize))
+}
+
+func bufferSize(cfg Config) int {
+	if cfg.BufferSize > 0 {
+		return cfg.BufferSize
+	}
+	return defaultBufferSize
+}
 
-		if cfg.Targets&OutputFile != 0 {
-			instance.fileLogger = &lumberjack.Logger{
-				Filename:   cfg.LogPath,
-				MaxSize:    10,
-				MaxBackups: 5,
-				MaxAge:     7,
-				Compress:   true,
+// applyConfig 在 start() 协程中切换配置，路径未变的文件写入器会被复用
+func (l *Logger) applyConfig(cfg Config, warnings []string) {
+	var fileLogger, allowFileLogger, jsonlLogger io.WriteCloser
+	var eventLog eventSink
+	var osLog osLogSink
+	if cfg.Targets&OutputFile != 0 {
+		// OnRotate 无法比较，设置了它时总是新建写入器
+		if l.fileLogger != nil && l.config.LogPath == cfg.LogPath && l.config.RotateDaily == cfg.RotateDaily &&
+			l.config.FileRotation.equal(cfg.FileRotation) && l.config.OnRotate == nil && cfg.OnRotate == nil {
+			fileLogger = l.fileLogger
+		} else {
+			fileLogger = newMainWriter(cfg)
+		}
+	}
+	if len(cfg.AllowedPrefix) > 0 {
+		if l.allowFileLogger != nil && l.config.AllowedRotation.equal(cfg.AllowedRotation) {
+			allowFileLogger = l.allowFileLogger
+		} else {
+			allowFileLogger = newFileWriter(allowedLogPath, cfg.AllowedRotation)
+		}
+	}
+	if cfg.JSONLPath != "" {
+		if l.jsonlLogger != nil && l.config.JSONLPath == cfg.JSONLPath && l.config.FileRotation.equal(cfg.FileRotation) {
+			jsonlLogger = l.jsonlLogger
+		} else {
+			jsonlLogger = newFileWriter(cfg.JSONLPath, cfg.FileRotation)
+		}
+	}
+	if cfg.Targets&OutputEventLog != 0 {
+		if l.eventLog != nil && eventLogSource(l.config) == eventLogSource(cfg) {
+			eventLog = l.eventLog
+		} else {
+			sink, err := openEventLog(eventLogSource(cfg))
+			if err != nil {
+				warnings = append(warnings, err.Error())
 			}
+			eventLog = sink
 		}
-		if len(cfg.AllowedPrefix) > 0 {
-			instance.allowFileLogger = &lumberjack.Logger{
-				Filename:   "logs_allowed/allowed.log",
-				MaxSize:    10,
-				MaxBackups: 5,
-				MaxAge:     7,
-				Compress:   true,
+	}
+	if cfg.Targets&OutputOSLog != 0 {
+		if l.osLog != nil && osLogSubsystem(l.config) == osLogSubsystem(cfg) {
+			osLog = l.osLog
+		} else {
+			sink, err := openOSLog(osLogSubsystem(cfg))
+			if err != nil {
+				warnings = append(warnings, err.Error())
 			}
+			osLog = sink
 		}
+	}
+
+	l.sampler = newSampler(cfg.Sampling)
+	l.burst = newBurstDetector(cfg.AutoDebugOnErrorBurst)
+	l.mu.Lock()
+	oldFile, oldAllow, oldJSONL, oldEvent, oldOSLog := l.fileLogger, l.allowFileLogger, l.jsonlLogger, l.eventLog, l.osLog
+	l.config = cfg
+	l.storeHotConfig(cfg)
+	l.fileLogger, l.allowFileLogger, l.jsonlLogger, l.eventLog, l.osLog = fileLogger, allowFileLogger, jsonlLogger, eventLog, osLog
+	l.mu.Unlock()
+
+	if oldFile != nil && oldFile != fileLogger {
+		_ = oldFile.Close()
+	}
+	if oldAllow != nil && oldAllow != allowFileLogger {
+		_ = oldAllow.Close()
+	}
+	if oldJSONL != nil && oldJSONL != jsonlLogger {
+		_ = oldJSONL.Close()
+	}
+	if oldEvent != nil && oldEvent != eventLog {
+		_ = oldEvent.Close()
+	}
+	if oldOSLog != nil && oldOSLog != osLog {
+		_ = oldOSLog.Close()
+	}
+	for _, w := range warnings {
+		l.consoleWarn(w)
+	}
+}
 
-		go instance.start()
+// NewNop 返回一个丢弃所有日志的 Logger，不创建通道和协程，适合库代码作为默认值
+func NewNop() *Logger {
+	return &Logger{nop: true}
+}
+
+// Named 返回一个带子系统名称的派生 Logger，与原 Logger 共享通道和写入器。
+// 多次调用会以 "." 连接名称，例如 Named("db").Named("pool") 得到 "db.pool"。
+func (l *Logger) Named(name string) *Logger {
+	if l.nop || name == "" {
+		return l
+	}
+	child := *l
+	if child.component == "" {
+		child.component = name
+	} else {
+		child.component = child.component + "." + name
+	}
+	return &child
+}
+
+// validateConfig 检查配置并填充可以安全回退的字段，返回需要提示用户的警告
+func validateConfig(cfg Config) (Config, []string) {
+	var warnings []string
+	if cfg.Targets&OutputFile != 0 && cfg.LogPath == "" {
+		cfg.LogPath = DefaultLogPath
+		warnings = append(warnings, fmt.Sprintf("logger: OutputFile enabled but LogPath is empty, falling back to %s", DefaultLogPath))
+	}
+	return cfg, warnings
+}
+
+// consoleWarn 绕过异步通道，直接向控制台输出一条 WARN 日志
+func (l *Logger) consoleWarn(msg string) {
+	formatted := l.formatLog(logMsg{
+		Level:   WARN,
+		Message: msg,
+		Time:    time.Now(),
+		Caller:  "logger",
 	})
-	return instance
+	io.WriteString(l.consoleWriter(WARN), l.colorize(WARN, formatted))
+}
+
+// consoleWriter 按等级选择控制台输出流
+func (l *Logger) consoleWriter(level Level) io.Writer {
+	if l.config.ConsoleWriter != nil {
+		return l.config.ConsoleWriter
+	}
+	if l.config.ErrorsToStderr && level >= WARN {
+		return l.stderr
+	}
+	return l.stdout
 }
 
 func (l *Logger) start() {
+	defer close(l.done)
+	defer l.closeSubscribers()
+	defer l.closeWriters()
+
+	dropTicker := time.NewTicker(l.dropReportInterval())
+	defer dropTicker.Stop()
+	var flushTicker, heartbeatTicker *time.Ticker
+	flushC := resetTicker(&flushTicker, l.config.FlushInterval)
+	heartbeatC := resetTicker(&heartbeatTicker, l.config.HeartbeatInterval)
+	defer func() {
+		resetTicker(&flushTicker, 0)
+		resetTicker(&heartbeatTicker, 0)
+	}()
+
 	for {
 		select {
 		case msg := <-l.logChan:
-			formatted := l.formatLog(msg)
-
-			if l.config.Targets&OutputConsole != 0 {
-				fmt.Print(colorize(msg.Level, formatted))
+			l.write(msg)
+			if l.overflowing.Load() {
+				l.drainOverflow()
 			}
-			if l.config.Targets&OutputFile != 0 {
-				l.fileLogger.Write([]byte(formatted))
+		case <-l.overflowReady:
+			if l.overflowing.Load() {
+				l.drainOverflow()
 			}
-
-			if l.allowFileLogger != nil && l.shouldAllow(msg.Caller) {
-				l.allowFileLogger.Write([]byte(formatted))
+		case <-dropTicker.C:
+			l.reportDropped()
+		case <-flushC:
+			l.syncFiles()
+		case <-heartbeatC:
+			l.heartbeat()
+		case req := <-l.ctrl:
+			// 先写完已入队的日志，保证它们使用旧配置
+			for n := len(l.logChan); n > 0; n-- {
+				l.write(<-l.logChan)
 			}
-		case <-l.quit:
-			close(l.logChan)
-			for msg := range l.logChan {
-				formatted := l.formatLog(msg)
-				if l.config.Targets&OutputFile != 0 {
-					l.fileLogger.Write([]byte(formatted))
-				}
-				if l.allowFileLogger != nil && l.shouldAllow(msg.Caller) {
-					l.allowFileLogger.Write([]byte(formatted))
-				}
+			if l.overflowing.Load() {
+				l.drainOverflow()
+			}
+			if req.cfg != nil {
+				l.applyConfig(*req.cfg, req.warnings)
+				dropTicker.Reset(l.dropReportInterval())
+				flushC = resetTicker(&flushTicker, l.config.FlushInterval)
+				heartbeatC = resetTicker(&heartbeatTicker, l.config.HeartbeatInterval)
+			}
+			if req.allowed != nil {
+				l.applyAllowed(req.allowed)
 			}
+			if req.sync {
+				l.unsynced = true
+				l.syncFiles()
+			}
+			if req.reopen {
+				*req.err = l.reopenFiles()
+			}
+			close(req.done)
+		case <-l.quit:
+			// Close 已置位 closed，之后的入队直接丢弃；通道从不关闭，
+			// 在途的入队调用完成前持续接收，保证它们不会阻塞也不会丢失。
+			// CloseContext 的 ctx 到期后不再等待，仍未完成的入队在 done 关闭后计为丢弃
+			l.awaitInflight()
+			l.drainOverflow()
+			l.reportDropped()
+			l.writeSummary()
+			return
+		}
+	}
+}
+
+// awaitInflight 在关闭时继续接收日志，直到在途的入队调用全部返回或 closeDeadline 到期
+func (l *Logger) awaitInflight() {
+	for l.inflight.Load() > 0 {
+		select {
+		case msg := <-l.logChan:
+			l.write(msg)
+		case <-l.overflowReady:
+		case <-l.inflightIdle:
+		case <-l.closeDeadline:
 			return
 		}
+		if l.overflowing.Load() {
+			l.drainOverflow()
+		}
+	}
+}
+
+// resetTicker 停止 *t 并按间隔 d 重新创建，d 为 0 时返回 nil 通道（select 中永不就绪）
+func resetTicker(t **time.Ticker, d time.Duration) <-chan time.Time {
+	if *t != nil {
+		(*t).Stop()
+		*t = nil
+	}
+	if d <= 0 {
+		return nil
+	}
+	*t = time.NewTicker(d)
+	return (*t).C
+}
+
+// heartbeat 输出一条包含协程数与内存统计的 INFO 日志，不受 MinLevel 限制，只在 start() 中调用
+func (l *Logger) heartbeat() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	l.pending.Add(1)
+	l.write(logMsg{
+		Level:   INFO,
+		Message: "heartbeat",
+		Time:    time.Now(),
+		Caller:  "logger",
+		Fields: []Field{
+			{Key: "goroutines", Value: runtime.NumGoroutine()},
+			{Key: "heap_alloc", Value: ms.HeapAlloc},
+			{Key: "num_gc", Value: ms.NumGC},
+		},
+	})
+}
+
+// syncFiles 把上次同步之后有新写入的文件写入器同步到磁盘，只在 start() 中调用
+func (l *Logger) syncFiles() {
+	if !l.unsynced {
+		return
+	}
+	l.unsynced = false
+	if l.fileLogger != nil {
+		_ = syncWriter(l.fileLogger)
+	}
+	if l.allowFileLogger != nil {
+		_ = syncWriter(l.allowFileLogger)
+	}
+	if l.jsonlLogger != nil {
+		_ = syncWriter(l.jsonlLogger)
+	}
+}
+
+func (l *Logger) dropReportInterval() time.Duration {
+	if l.config.DropReportInterval > 0 {
+		return l.config.DropReportInterval
+	}
+	return defaultDropReportInterval
+}
+
+// reportDropped 在有新增丢弃时直接写出一条 WARN 汇总，不经过通道，因此自身不会被丢弃
+func (l *Logger) reportDropped() {
+	n := l.droppedInterval.Swap(0)
+	if n == 0 {
+		return
+	}
+	l.pending.Add(1)
+	l.write(logMsg{
+		Level:   WARN,
+		Message: fmt.Sprintf("dropped %d messages in the last interval", n),
+		Time:    time.Now(),
+		Caller:  "logger",
+		Fields:  []Field{{Key: "dropped", Value: n}},
+	})
+}
+
+func (l *Logger) write(msg logMsg) {
+	defer l.pending.Add(-1)
+	l.latency.observe(time.Since(msg.Time))
+	if l.sampler != nil && !l.sampler.allow(msg) {
+		l.sampled.Add(1)
+		return
+	}
+	if msg.Level >= 0 && int(msg.Level) < numLevels {
+		l.written[msg.Level].Add(1)
+	}
+	r := renderings{l: l, msg: msg}
+	formats := l.formatsFor(msg)
+	allowed := l.allowFileLogger != nil && l.shouldAllow(msg)
+	markAllowed := allowed && l.config.AllowedToConsole
+
+	targets := l.config.Targets
+	if msg.OverrideTargets {
+		targets = msg.Targets
+	}
+
+	onConsole := targets&OutputConsole != 0 || markAllowed
+	if onConsole {
+		marker := ""
+		if markAllowed {
+			marker = allowedConsoleMarker
+		}
+		io.WriteString(l.consoleWriter(msg.Level), r.console(marker))
+	}
+	if targets&OutputFile != 0 && l.fileLogger == nil {
+		l.openFileLazily()
+	}
+	if targets&OutputFile != 0 && l.fileLogger != nil {
+		l.writeFile(&r, onConsole)
+	}
+
+	if targets&OutputEventLog != 0 && l.eventLog != nil {
+		_ = writeEvent(l.eventLog, msg.Level, r.bare(formats.format))
+	}
+	if targets&OutputOSLog != 0 && l.osLog != nil {
+		writeOSLog(l.osLog, msg.Level, r.bare(formats.format))
+	}
+
+	if allowed {
+		l.allowFileLogger.Write([]byte(r.file(formats.file())))
+		l.unsynced = true
+	}
+	if l.jsonlLogger != nil && !msg.Raw {
+		l.jsonlLogger.Write([]byte(r.jsonl()))
+		l.unsynced = true
+	}
+	if l.capture != nil {
+		l.capture.add(msg)
+	}
+	if len(l.config.Hooks) > 0 {
+		l.runHooks(msg)
+	}
+	if l.config.OnErrorAlert != nil && msg.Level >= ERROR && !msg.Raw {
+		l.alert(msg)
+	}
+	if l.subCount.Load() > 0 {
+		l.publish(msg)
+	}
+	if l.burst != nil && msg.Level >= ERROR && l.burst.observe(msg.Time) {
+		l.autoDebug()
+	}
+}
+
+func (l *Logger) closeWriters() {
+	if l.fileLogger != nil {
+		_ = l.fileLogger.Close()
+	}
+	if l.allowFileLogger != nil {
+		_ = l.allowFileLogger.Close()
+	}
+	if l.jsonlLogger != nil {
+		_ = l.jsonlLogger.Close()
+	}
+	if l.eventLog != nil {
+		_ = l.eventLog.Close()
+	}
+	if l.osLog != nil {
+		_ = l.osLog.Close()
 	}
 }
 
-func (l *Logger) shouldAllow(caller string) bool {
-	if len(l.config.AllowedPrefix) == 0 {
+// shouldAllow 报告日志是否写入白名单文件：等级不低于 AllowedMinLevel 且调用位置按 AllowedMatch 匹配任一前缀
+func (l *Logger) shouldAllow(msg logMsg) bool {
+	if len(l.config.AllowedPrefix) == 0 || msg.Level < l.config.AllowedMinLevel {
 		return false
 	}
 	for _, prefix := range l.config.AllowedPrefix {
-		if strings.Contains(caller, prefix) {
+		if matchCaller(l.config.AllowedMatch, msg, prefix) {
 			return true
 		}
 	}
 	return false
 }
 
-func (l *Logger) formatLog(msg logMsg) string {
-	if l.config.Format == FormatJSON {
-		data := map[string]interface{}{
-			"level":   levelToStr(msg.Level),
-			"time":    msg.Time.Format(time.RFC3339),
-			"message": msg.Message,
-			"caller":  msg.Caller,
+func matchCaller(field MatchField, msg logMsg, prefix string) bool {
+	switch field {
+	case MatchFile:
+		return msg.File != "" && strings.HasPrefix(msg.File, prefix)
+	case MatchFunc:
+		if msg.Func == "" {
+			return false
 		}
-		b, _ := json.Marshal(data)
-		return string(b) + "\n"
+		return strings.HasPrefix(msg.Func, prefix) || msg.Func[strings.LastIndexByte(msg.Func, '.')+1:] == prefix
+	default:
+		return strings.Contains(msg.Caller, prefix)
 	}
-	return fmt.Sprintf("[%s] %s %s %s\n",
-		levelToStr(msg.Level),
-		msg.Time.Format("2006-01-02 15:04:05"),
-		msg.Caller,
-		msg.Message,
-	)
 }
 
-func getCaller() string {
-	pc, file, line, ok := runtime.Caller(3)
-	if !ok {
-		return "unknown"
+var defaultLevelColors = map[Level]string{
+	TRACE: "\033[90m",   // Gray
+	DEBUG: "\033[36m",   // Cyan
+	INFO:  "\033[32m",   // Green
+	WARN:  "\033[33m",   // Yellow
+	ERROR: "\033[31m",   // Red
+	FATAL: "\033[1;31m", // Bold red
+}
+
+// colorize 为控制台输出着色，优先使用 Config.LevelColors 中的配置
+func (l *Logger) colorize(level Level, msg string) string {
+	if l.config.Formatter != nil {
+		return msg
 	}
-	fn := runtime.FuncForPC(pc).Name()
-	parts := strings.Split(fn, "/")
-	shortFunc := parts[len(parts)-1]
-	parts = strings.Split(file, "/")
-	shortFile := parts[len(parts)-1]
-	return fmt.Sprintf("%s:%d %s", shortFile, line, shortFunc)
+	color := l.config.LevelColors[level]
+	if color == "" {
+		color = defaultLevelColors[level]
+	}
+	if color == "" {
+		return msg
+	}
+	return color + msg + "\033[0m"
 }
 
-func colorize(level Level, msg string) string {
-	switch level {
-	case DEBUG:
-		return "\033[36m" + msg + "\033[0m" // Cyan
-	case INFO:
-		return "\033[32m" + msg + "\033[0m" // Green
-	case WARN:
-		return "\033[33m" + msg + "\033[0m" // Yellow
-	case ERROR:
-		return "\033[31m" + msg + "\033[0m" // Red
-	default:
+// enabled 报告该等级是否可能被输出；配置了 PrefixLevels 时最终结果要等拿到 caller 后在 log 中确定
+func (l *Logger) enabled(level Level) bool {
+	if l.nop {
+		return false
+	}
+	if level >= l.levelFloor() {
+		return true
+	}
+	p := l.prefixLevels.Load()
+	return p != nil && level >= p.min
+}
+
+// Enabled 报告该等级的日志是否会被输出，可在构造开销较大的日志内容前先行判断。
+// 配置了 PrefixLevels 时，只要某个前缀允许该等级就返回 true。
+func (l *Logger) Enabled(level Level) bool {
+	return l.enabled(level)
+}
+
+// SetLevel 在运行时修改最低输出等级，可与日志调用并发执行。
+// 对 Clone 派生的 Logger 只修改克隆自己的等级，不影响原 Logger。
+func (l *Logger) SetLevel(level Level) {
+	if l.nop {
+		return
+	}
+	s := l.levels()
+	s.cancel()
+	s.set(level)
+}
+
+// set 只更新原子副本：config 仅由 start() 修改，这里写入会与写日志时对 config 的读取竞争
+func (s *levelState) set(level Level) {
+	s.minLevel.Store(int32(level))
+}
+
+// BoostLevel 临时把最低等级改为 level，d 之后自动恢复为提升前的等级。
+// 重叠调用时以最后一次为准：等级与时长都被替换，到期后仍恢复为首次提升前的等级；
+// 期间调用 SetLevel 或 Reconfigure 会取消提升。与 SetLevel 一样，对克隆只作用于克隆自己的等级。
+func (l *Logger) BoostLevel(level Level, d time.Duration) {
+	if l.nop {
+		return
+	}
+	s := l.levels()
+	s.boostMu.Lock()
+	defer s.boostMu.Unlock()
+	if s.boostTimer != nil {
+		s.boostTimer.Stop()
+	} else {
+		s.boostBase = Level(s.minLevel.Load())
+	}
+	s.set(level)
+	var t *time.Timer
+	t = time.AfterFunc(d, func() {
+		s.boostMu.Lock()
+		defer s.boostMu.Unlock()
+		// 已被新的提升替换或被取消
+		if s.boostTimer != t {
+			return
+		}
+		s.boostTimer = nil
+		s.set(s.boostBase)
+	})
+	s.boostTimer = t
+}
+
+// cancel 停止尚未到期的 BoostLevel 并丢弃 PushLevel 未恢复的层，不恢复等级
+func (s *levelState) cancel() {
+	s.boostMu.Lock()
+	defer s.boostMu.Unlock()
+	if s.boostTimer != nil {
+		s.boostTimer.Stop()
+		s.boostTimer = nil
+	}
+	s.levelStack = nil
+}
+
+// GetLevel 返回当前最低输出等级
+func (l *Logger) GetLevel() Level {
+	if l.nop {
+		return levelOff
+	}
+	return l.levelFloor()
+}
+
+// Config 返回当前生效配置的副本：包含 Reconfigure、SetLevel 的结果，并填入实际使用的默认值
+// （轮转设置、DropReportInterval、IncludeCaller、EventLogSource）。修改返回值不影响 Logger。
+func (l *Logger) Config() Config {
+	if l.nop {
+		return Config{MinLevel: levelOff, Targets: OutputNone}
+	}
+	l.mu.RLock()
+	cfg := l.config
+	l.mu.RUnlock()
+	cfg.MinLevel = l.levelFloor()
+	if l.clone != nil {
+		cfg.Format, cfg.ConsoleFormat, cfg.FileFormat = l.clone.formats.format, l.clone.formats.consoleFormat, l.clone.formats.fileFormat
+	}
+	if l.targets != nil {
+		cfg.Targets = *l.targets
+	}
+	return cfg.resolved()
+}
+
+// resolved 深拷贝引用类型字段并填入默认值
+func (cfg Config) resolved() Config {
+	cfg.AllowedPrefix = slices.Clone(cfg.AllowedPrefix)
+	cfg.MaskKeys = slices.Clone(cfg.MaskKeys)
+	cfg.Hooks = slices.Clone(cfg.Hooks)
+	cfg.LevelColors = maps.Clone(cfg.LevelColors)
+	cfg.PrefixLevels = maps.Clone(cfg.PrefixLevels)
+	cfg.ConsoleFormat = clonePtr(cfg.ConsoleFormat)
+	cfg.FileFormat = clonePtr(cfg.FileFormat)
+	cfg.Sampling = clonePtr(cfg.Sampling)
+	cfg.AutoDebugOnErrorBurst = clonePtr(cfg.AutoDebugOnErrorBurst)
+	cfg.FileRotation = cfg.FileRotation.withDefaults()
+	cfg.FileRotation.Compress = clonePtr(cfg.FileRotation.Compress)
+	cfg.AllowedRotation = cfg.AllowedRotation.withDefaults()
+	cfg.AllowedRotation.Compress = clonePtr(cfg.AllowedRotation.Compress)
+	if cfg.DropReportInterval <= 0 {
+		cfg.DropReportInterval = defaultDropReportInterval
+	}
+	includeCaller := cfg.IncludeCaller == nil || *cfg.IncludeCaller
+	cfg.IncludeCaller = &includeCaller
+	if cfg.Targets&OutputEventLog != 0 {
+		cfg.EventLogSource = eventLogSource(cfg)
+	}
+	if cfg.Targets&OutputOSLog != 0 {
+		cfg.OSLogSubsystem = osLogSubsystem(cfg)
+	}
+	return cfg
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// log 是所有日志入口的汇合点。depth 为用户调用处与 log 之间的包内栈帧数
+// （不含 log 本身），例如 Info 直接调用 log 时 depth 为 1；
+// 多包一层的入口需要相应加一，才能让 caller 指向用户代码。
+func (l *Logger) log(depth int, level Level, msg string, fields []Field) {
+	l.logCtx(context.Background(), depth+1, level, msg, fields)
+}
+
+// logCtx 与 log 相同，但阻塞入队时 ctx 结束即放弃发送并计为丢弃
+func (l *Logger) logCtx(ctx context.Context, depth int, level Level, msg string, fields []Field) {
+	if !l.enabled(level) {
+		return
+	}
+	// PrefixLevels 依赖 caller，必须先取调用位置再做最终的等级判断
+	p := l.prefixLevels.Load()
+	var c callerInfo
+	var ok bool
+	if p != nil || !l.noCaller.Load() {
+		c, ok = getCallerInfo(depth + 1)
+	}
+	if p != nil && level < p.level(c.FullFunc, l.levelFloor()) {
+		return
+	}
+	fields = nestFields(l.groups, fields)
+	if len(l.fields) > 0 {
+		fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	}
+	if l.gated {
+		fields = resolveGated(fields, level)
+	}
+	if limit := int(l.maxMessageBytes.Load()); limit > 0 {
+		msg = truncateMessage(msg, limit)
+	}
+	m := logMsg{
+		Level:     level,
+		Message:   msg,
+		Time:      time.Now(),
+		Fields:    fields,
+		Component: l.component,
+	}
+	if l.targets != nil {
+		m.Targets, m.OverrideTargets = *l.targets, true
+	}
+	if l.clone != nil {
+		m.Formats = &l.clone.formats
+	}
+	if !l.noCaller.Load() {
+		m.setCaller(c, ok)
+	}
+	l.enqueueCtx(ctx, m)
+}
+
+// truncateMessage 把超过 limit 字节的消息截断到 limit 以内最近的 UTF-8 字符边界，
+// 保证截断后在 JSON 中仍是合法字符串
+func truncateMessage(msg string, limit int) string {
+	if len(msg) <= limit {
 		return msg
 	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", msg[:cut], len(msg)-cut)
 }
 
-func (l *Logger) log(level Level, msg string) {
-	if level < l.config.MinLevel {
+// logInternal 以 "logger" 作为 caller 输出包内部产生的日志，仍受 MinLevel 限制
+func (l *Logger) logInternal(level Level, msg string, fields ...Field) {
+	if !l.enabled(level) {
 		return
 	}
-	l.logChan <- logMsg{
+	l.enqueue(logMsg{
 		Level:   level,
 		Message: msg,
 		Time:    time.Now(),
-		Caller:  getCaller(),
+		Caller:  "logger",
+		Fields:  fields,
+	})
+}
+
+func (l *Logger) enqueue(msg logMsg) {
+	l.enqueueCtx(context.Background(), msg)
+}
+
+// enqueueCtx 按溢出策略入队：OverflowDrop 时通道满即丢弃，
+// OverflowBlock 时阻塞直到入队、ctx 结束或 EnqueueTimeout 到期，后两者同样计为丢弃；Close 之后的日志也计为丢弃；
+// 设置了 OverflowBufferSize 时通道满先进入溢出队列，队列也满才按上述策略处理；
+// 配置了 FallbackWriter 时，原本会丢弃或阻塞的日志改为直接写入它
+func (l *Logger) enqueueCtx(ctx context.Context, msg logMsg) {
+	// 先登记在途再检查 closed：start() 看到 inflight 为 0 之后开始的入队必然看到 closed
+	l.inflight.Add(1)
+	defer func() {
+		if l.inflight.Add(-1) == 0 && l.closed.Load() {
+			select {
+			case l.inflightIdle <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	l.pending.Add(1)
+	if l.closed.Load() {
+		l.countDrop()
+		return
+	}
+	if l.includeSeq.Load() {
+		msg.Seq = l.seq.Add(1)
+	}
+	if l.synchronous {
+		l.writeSync(msg)
+		return
+	}
+	if l.maxOverflow.Load() > 0 || l.overflowing.Load() {
+		if !l.overflowing.Load() {
+			select {
+			case l.logChan <- msg:
+				return
+			default:
+			}
+		}
+		l.enqueueOverflow(ctx, msg)
+		return
+	}
+	select {
+	case l.logChan <- msg:
+		return
+	default:
+	}
+	if l.writeFallback(msg) {
+		return
+	}
+	if l.dropOnFull.Load() {
+		l.countDrop()
+		return
+	}
+	done, expired := ctx.Done(), l.enqueueExpiry()
+	select {
+	case l.logChan <- msg:
+	case <-done:
+		l.countDrop()
+	case <-expired:
+		l.countDrop()
+	case <-l.done:
+		// Close 等待超时后 start() 已退出，不会再有人接收
+		l.countDrop()
+	}
+}
+
+// enqueueExpiry 返回 EnqueueTimeout 到期时就绪的通道，未设置时返回 nil（select 中永不就绪）
+func (l *Logger) enqueueExpiry() <-chan time.Time {
+	timeout := time.Duration(l.enqueueTimeout.Load())
+	if timeout <= 0 {
+		return nil
+	}
+	return time.After(timeout)
+}
+
+func (l *Logger) countDrop() {
+	l.pending.Add(-1)
+	l.dropped.Add(1)
+	l.droppedInterval.Add(1)
+}
+
+func (l *Logger) Trace(msg string) { l.log(1, TRACE, msg, nil) }
+func (l *Logger) Info(msg string)  { l.log(1, INFO, msg, nil) }
+func (l *Logger) Error(msg string) { l.log(1, ERROR, msg, nil) }
+func (l *Logger) Debug(msg string) { l.log(1, DEBUG, msg, nil) }
+func (l *Logger) Warn(msg string)  { l.log(1, WARN, msg, nil) }
+
+// Closed 返回一个在 Close 之后、所有日志写出且文件关闭时关闭的通道，
+// 便于嵌入本库的代码在 Logger 完全停止后再继续自己的清理。NewNop 返回已关闭的通道。
+func (l *Logger) Closed() <-chan struct{} {
+	if l.nop {
+		return closedChan
 	}
+	return l.done
 }
 
-func (l *Logger) Info(msg string)  { l.log(INFO, msg) }
-func (l *Logger) Error(msg string) { l.log(ERROR, msg) }
-func (l *Logger) Debug(msg string) { l.log(DEBUG, msg) }
-func (l *Logger) Warn(msg string)  { l.log(WARN, msg) }
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
 
+// Close 等待所有已入队日志写出后关闭 Logger。之后的日志调用不会 panic，直接计为丢弃；
+// 与 Close 并发的日志调用要么被写出，要么计为丢弃。
 func (l *Logger) Close() {
-	close(l.quit)
-	if l.fileLogger != nil {
-		_ = l.fileLogger.Close()
+	_ = l.CloseContext(context.Background())
+}
+
+// CloseContext 停止接收并排空日志；ctx 到期时直接返回 *CloseError，
+// 其中记录尚未写出的日志数，后台协程仍会继续写完已入队的日志，但不再等待仍在入队的调用。
+func (l *Logger) CloseContext(ctx context.Context) error {
+	if l.nop {
+		return nil
 	}
-	if l.allowFileLogger != nil {
-		_ = l.allowFileLogger.Close()
+	l.closeOnce.Do(func() {
+		if l.synchronous {
+			l.closeSync()
+			return
+		}
+		l.closeDeadline = ctx.Done()
+		l.closed.Store(true)
+		close(l.quit)
+	})
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return &CloseError{Unflushed: int(l.pending.Load()), Err: ctx.Err()}
 	}
 }
 
 func RecoverAndLogPanic() {
 	if r := recover(); r != nil {
-		buf := make([]byte, 4096)
-		n := runtime.Stack(buf, false)
-		msg := fmt.Sprintf("Panic recovered: %v\n%s", r, string(buf[:n]))
-
-		log := GetLoggerInstance()
-		formatted := log.formatLog(logMsg{
-			Level:   ERROR,
-			Message: msg,
-			Time:    time.Now(),
-			Caller:  getCaller(),
-		})
-
-		if log.config.Targets&OutputConsole != 0 {
-			fmt.Print(colorize(ERROR, formatted))
-		}
-		if log.config.Targets&OutputFile != 0 && log.fileLogger != nil {
-			log.fileLogger.Write([]byte(formatted))
+		// 栈帧：RecoverAndLogPanic -> runtime.gopanic -> 发生 panic 的用户函数
+		GetLoggerInstance().logPanic(r, 2)
+	}
+}
+
+// RecoverAndLogPanicWith 与 RecoverAndLogPanic 相同，但按 opts 调整记录的内容，需直接 defer 调用
+func RecoverAndLogPanicWith(opts PanicOptions) {
+	if r := recover(); r != nil {
+		GetLoggerInstance().logPanicWith(r, 2, opts)
+	}
+}
+
+// RecoverAndLogPanicRethrow 记录 panic 并把所有日志写出、同步到磁盘后，以原值重新 panic，
+// 让进程照常崩溃、由上层的进程管理器感知，同时保证崩溃前的日志不会丢失。需直接 defer 调用。
+func RecoverAndLogPanicRethrow() {
+	if r := recover(); r != nil {
+		GetLoggerInstance().logPanicRethrow(r, 2)
+	}
+}
+
+// logPanicRethrow 记录 panic，等待已入队日志写出并同步文件后重新 panic
+func (l *Logger) logPanicRethrow(r interface{}, depth int) {
+	l.logPanic(r, depth+1)
+	if !l.nop && l.control(controlReq{sync: true}) == ErrClosed {
+		<-l.done
+	}
+	panic(r)
+}
+
+// logPanic 绕过异步通道同步写出 panic 信息，depth 含义与 log 相同
+func (l *Logger) logPanic(r interface{}, depth int) {
+	l.logPanicWith(r, depth+1, PanicOptions{})
+}
+
+func (l *Logger) logPanicWith(r interface{}, depth int, opts PanicOptions) {
+	if l.nop {
+		return
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	m := logMsg{
+		Level:     ERROR,
+		Time:      time.Now(),
+		Component: l.component,
+	}
+	if !l.noCaller.Load() {
+		m.setCaller(getCallerInfo(depth + 1))
+	}
+
+	// 每个目标按自己的格式选择：StructuredPanic 下 JSON/ECS 输出 panic 与 stack 字段，其余格式输出文本栈
+	text := m
+	text.Message = fmt.Sprintf("Panic recovered: %v\n%s", r, opts.annotateStack(panicStack(l.config.PanicStackSize)))
+	textOut := renderings{l: l, msg: text}
+	structuredOut := textOut
+	if l.config.StructuredPanic {
+		s := m
+		s.Message = "Panic recovered"
+		s.Fields = []Field{
+			{Key: "panic", Value: fmt.Sprint(r)},
+			{Key: "stack", Value: opts.annotateFrames(callerFrames(depth + 1))},
 		}
-		if log.allowFileLogger != nil && log.shouldAllow(getCaller()) {
-			log.allowFileLogger.Write([]byte(formatted))
+		structuredOut = renderings{l: l, msg: s}
+	}
+	out := func(f Format) *renderings {
+		if f == FormatJSON || f == FormatECS {
+			return &structuredOut
 		}
+		return &textOut
+	}
+
+	if l.config.Targets&OutputConsole != 0 {
+		io.WriteString(l.consoleWriter(ERROR), out(l.config.consoleFormat()).console(""))
+	}
+	fileFormat := l.config.fileFormat()
+	if l.config.Targets&OutputFile != 0 && l.fileLogger != nil {
+		l.fileLogger.Write([]byte(out(fileFormat).file(fileFormat)))
+	}
+	if l.config.Targets&OutputEventLog != 0 && l.eventLog != nil {
+		_ = writeEvent(l.eventLog, ERROR, out(l.config.Format).bare(l.config.Format))
+	}
+	if l.config.Targets&OutputOSLog != 0 && l.osLog != nil {
+		writeOSLog(l.osLog, ERROR, out(l.config.Format).bare(l.config.Format))
+	}
+	if l.allowFileLogger != nil && l.shouldAllow(m) {
+		l.allowFileLogger.Write([]byte(out(fileFormat).file(fileFormat)))
+	}
+	if l.jsonlLogger != nil {
+		l.jsonlLogger.Write([]byte(out(FormatJSON).jsonl()))
 	}
 }
@@ -1,17 +1,29 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
 // 测试初始化、日志写入、通道关闭等核心逻辑
 func TestLoggerBasic(t *testing.T) {
+	t.Chdir(t.TempDir()) // 白名单文件写到临时目录，不落在仓库里
 	cfg := Config{
 		MinLevel:      DEBUG,
 		Format:        FormatPlain,
 		Targets:       OutputConsole, // 只控制台，避免文件IO影响测试
+		Synchronous:   true,          // 同步写出，无需等待后台协程
 		LogPath:       "logs/test.log",
 		AllowedPrefix: []string{"logger"},
 	}
@@ -24,23 +36,19 @@ func TestLoggerBasic(t *testing.T) {
 	log.Warn("warn msg")
 	log.Error("error msg")
 
-	// 简单延迟，确保日志写入协程处理完
-	time.Sleep(100 * time.Millisecond)
-
-	// 由于日志写入是异步的，直接检测通道是否关闭前，先关闭
 	log.Close()
 
-	// 关闭后，不能再写入日志
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Expected panic on writing after Close, but no panic occurred")
-		}
-	}()
-	log.Info("写入关闭后日志，应panic")
+	// 关闭后写入日志不会 panic，直接计为丢弃
+	dropped := log.Stats().Dropped
+	log.Info("写入关闭后日志，应丢弃")
+	if got := log.Stats().Dropped; got != dropped+1 {
+		t.Errorf("Dropped after logging past Close = %d; want %d", got, dropped+1)
+	}
 }
 
 // 测试 RecoverAndLogPanic 捕获 panic 的逻辑
 func TestRecoverAndLogPanic(t *testing.T) {
+	t.Chdir(t.TempDir()) // 单例配置了白名单，panic 记录会写白名单文件
 	defer func() {
 		if r := recover(); r != nil {
 			t.Errorf("RecoverAndLogPanic did not catch panic, got: %v", r)
@@ -55,6 +63,7 @@ func TestRecoverAndLogPanic(t *testing.T) {
 
 // 测试 shouldAllow 功能
 func TestShouldAllow(t *testing.T) {
+	t.Chdir(t.TempDir())
 	cfg := Config{
 		AllowedPrefix: []string{"logger"},
 	}
@@ -72,17 +81,1796 @@ func TestShouldAllow(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		got := log.shouldAllow(c.caller)
+		got := log.shouldAllow(logMsg{Level: INFO, Caller: c.caller})
 		if got != c.allow {
 			t.Errorf("shouldAllow(%q) = %v; want %v", c.caller, got, c.allow)
 		}
 	}
 }
 
+// 测试 FromContext 在 ctx 中没有 Logger 时返回单例
+func TestFromContextDefault(t *testing.T) {
+	if got := FromContext(context.Background()); got != GetLoggerInstance() {
+		t.Errorf("FromContext(empty) = %p; want the singleton %p", got, GetLoggerInstance())
+	}
+}
+
 // 测试 getCaller 返回合理格式（略做简单断言）
 func TestGetCallerFormat(t *testing.T) {
-	caller := getCaller()
-	if !strings.Contains(caller, "asm_amd64") && !strings.Contains(caller, "runtime.goexit") {
+	caller := getCaller(0)
+	if !strings.Contains(caller, "logger_test.go:") || !strings.Contains(caller, "TestGetCallerFormat") {
 		t.Errorf("getCaller returned unexpected value: %s", caller)
 	}
 }
+
+// slowWriter 每次写入都阻塞一段时间，模拟卡住的下游
+type slowWriter struct {
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+func (w *slowWriter) Close() error { return nil }
+
+// 测试 CloseContext 在下游写入缓慢时按时返回并报告未写出的条数
+func TestCloseContextTimeout(t *testing.T) {
+	log := New(Config{
+		MinLevel: DEBUG,
+		Targets:  OutputFile,
+		LogPath:  filepath.Join(t.TempDir(), "slow.log"),
+	})
+	log.fileLogger = &slowWriter{delay: 50 * time.Millisecond}
+
+	for i := 0; i < 10; i++ {
+		log.Info("slow msg")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := log.CloseContext(ctx)
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Fatalf("CloseContext took %v, expected to return near the deadline", elapsed)
+	}
+
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("expected *CloseError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", closeErr.Err)
+	}
+	if closeErr.Unflushed <= 0 || closeErr.Unflushed > 10 {
+		t.Errorf("Unflushed = %d; want between 1 and 10", closeErr.Unflushed)
+	}
+}
+
+// 测试关闭时等待在途入队不超过 CloseContext 的 ctx：卡住的入队调用不会让后台协程一直等下去
+func TestCloseContextBoundsInflightWait(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO})
+	log.inflight.Add(1) // 模拟一个一直没有返回的入队调用
+	defer log.inflight.Add(-1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := log.CloseContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CloseContext = %v; want DeadlineExceeded", err)
+	}
+	select {
+	case <-log.Closed():
+	case <-time.After(2 * time.Second):
+		t.Fatal("start() still waiting for the in-flight enqueue after the close deadline")
+	}
+}
+
+// 测试自定义等级颜色覆盖默认值，未设置的等级保持默认
+func TestLevelColors(t *testing.T) {
+	log := &Logger{core: &core{config: Config{
+		LevelColors: map[Level]string{INFO: "\033[34m"},
+	}}}
+
+	if got := log.colorize(INFO, "msg"); got != "\033[34mmsg\033[0m" {
+		t.Errorf("colorize(INFO) = %q; want custom blue", got)
+	}
+	if got := log.colorize(ERROR, "msg"); got != "\033[31mmsg\033[0m" {
+		t.Errorf("colorize(ERROR) = %q; want default red", got)
+	}
+}
+
+// 测试启用文件输出但 LogPath 为空时回退到默认路径并给出警告
+func TestValidateConfigEmptyLogPath(t *testing.T) {
+	got, warnings := validateConfig(Config{Targets: OutputFile})
+	if got.LogPath != DefaultLogPath {
+		t.Errorf("LogPath = %q; want %q", got.LogPath, DefaultLogPath)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "LogPath is empty") {
+		t.Errorf("warnings = %v; want one LogPath warning", warnings)
+	}
+
+	// 未启用文件输出时不应修改配置
+	got, warnings = validateConfig(Config{Targets: OutputConsole})
+	if got.LogPath != "" || len(warnings) != 0 {
+		t.Errorf("unexpected fallback for console-only config: %q %v", got.LogPath, warnings)
+	}
+}
+
+// 测试 NewNop 的所有方法都不会 panic、不会分配，且 Close 可以重复调用
+func TestNopLogger(t *testing.T) {
+	log := NewNop()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Debug("debug")
+		log.Info("info")
+		log.Warn("warn")
+		log.Error("error")
+		log.Infow("info", "k", "v")
+	})
+	if allocs != 0 {
+		t.Errorf("nop logger allocated %v times per run; want 0", allocs)
+	}
+	log.Debugw("debug", "k", 1)
+	log.Warnw("warn", "k", 1)
+	log.Errorw("error", "k", 1)
+
+	if log.core != nil {
+		t.Errorf("nop logger should not create a channel or goroutine")
+	}
+
+	log.Close()
+	log.Close()
+	if err := log.CloseContext(context.Background()); err != nil {
+		t.Errorf("CloseContext on nop logger = %v; want nil", err)
+	}
+}
+
+// syncBuffer 是并发安全的内存 Writer，用于替换文件输出以便断言内容
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Close() error { return nil }
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newBufferLogger 创建一个文件输出被替换为内存缓冲的独立 Logger
+func newBufferLogger(t *testing.T, cfg Config) (*Logger, *syncBuffer) {
+	t.Helper()
+	t.Chdir(t.TempDir()) // 配置了 AllowedPrefix 时白名单文件写到临时目录
+	cfg.Targets = OutputFile
+	cfg.LogPath = filepath.Join(t.TempDir(), "test.log")
+	log := New(cfg)
+	buf := &syncBuffer{}
+	log.fileLogger = buf
+	return log, buf
+}
+
+// 测试 TRACE 等级的过滤
+func TestTraceLevelFiltering(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG})
+	log.Trace("hidden trace")
+	log.Debug("visible debug")
+	log.Close()
+	if out := buf.String(); strings.Contains(out, "hidden trace") || !strings.Contains(out, "visible debug") {
+		t.Errorf("MinLevel=DEBUG output = %q", out)
+	}
+
+	log, buf = newBufferLogger(t, Config{Synchronous: true, MinLevel: TRACE})
+	log.Trace("visible trace")
+	log.Tracew("trace fields", "i", 1)
+	log.Close()
+	if out := buf.String(); !strings.Contains(out, "[TRACE]") || !strings.Contains(out, "trace fields i=1") {
+		t.Errorf("MinLevel=TRACE output = %q", out)
+	}
+}
+
+// 测试等级名称的解析与按名称序列化
+func TestLevelNames(t *testing.T) {
+	for _, lv := range []Level{TRACE, DEBUG, INFO, WARN, ERROR} {
+		text, err := lv.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%d): %v", lv, err)
+		}
+		var parsed Level
+		if err := parsed.UnmarshalText(text); err != nil || parsed != lv {
+			t.Errorf("round trip %s = %v, %v", text, parsed, err)
+		}
+	}
+	if lv, err := ParseLevel("trace"); err != nil || lv != TRACE {
+		t.Errorf("ParseLevel(trace) = %v, %v", lv, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("ParseLevel(verbose) expected error")
+	}
+
+	b, err := json.Marshal(struct{ Level Level }{TRACE})
+	if err != nil || string(b) != `{"Level":"TRACE"}` {
+		t.Errorf("json.Marshal(TRACE) = %s, %v", b, err)
+	}
+}
+
+// 测试 ErrorsToStderr 按等级将控制台输出分流到 stdout / stderr
+func TestErrorsToStderrRouting(t *testing.T) {
+	log := New(Config{MinLevel: DEBUG, Targets: OutputConsole, ErrorsToStderr: true})
+	stdout, stderr := &syncBuffer{}, &syncBuffer{}
+	log.stdout, log.stderr = stdout, stderr
+
+	log.Debug("debug line")
+	log.Info("info line")
+	log.Warn("warn line")
+	log.Error("error line")
+	log.Close()
+
+	out, errOut := stdout.String(), stderr.String()
+	for _, s := range []string{"debug line", "info line"} {
+		if !strings.Contains(out, s) || strings.Contains(errOut, s) {
+			t.Errorf("%q should go to stdout only; stdout=%q stderr=%q", s, out, errOut)
+		}
+	}
+	for _, s := range []string{"warn line", "error line"} {
+		if !strings.Contains(errOut, s) || strings.Contains(out, s) {
+			t.Errorf("%q should go to stderr only; stdout=%q stderr=%q", s, out, errOut)
+		}
+	}
+}
+
+// 测试 Named 的子系统名称在两种格式中输出并可链式拼接
+func TestNamedLogger(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG, Format: FormatJSON})
+	db := log.Named("db")
+	db.Info("db msg")
+	db.Named("pool").Warn("pool msg")
+	log.Info("root msg")
+	log.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %q", buf.String())
+	}
+	want := []string{"db", "db.pool", ""}
+	for i, line := range lines {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		got, _ := data["component"].(string)
+		if got != want[i] {
+			t.Errorf("line %d component = %q; want %q", i, got, want[i])
+		}
+	}
+
+	plain := (&Logger{core: &core{config: Config{Format: FormatPlain}}}).formatLog(logMsg{
+		Level: INFO, Message: "hello", Component: "db.pool",
+	})
+	if !strings.Contains(plain, "[db.pool] hello") {
+		t.Errorf("plain output = %q; want component prefix", plain)
+	}
+}
+
+// 测试运行中从纯文本切换到 JSON，之后的日志使用新格式
+func TestReconfigurePlainToJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reconf.log")
+	cfg := Config{MinLevel: DEBUG, Format: FormatPlain, Targets: OutputFile, LogPath: path}
+	log := New(cfg)
+
+	log.Info("before 1")
+	log.Info("before 2")
+
+	cfg.Format = FormatJSON
+	if err := log.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	log.Info("after 1")
+	log.Info("after 2")
+	log.Close()
+
+	if err := log.Reconfigure(cfg); !errors.Is(err, ErrClosed) {
+		t.Errorf("Reconfigure after Close = %v; want ErrClosed", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %q", data)
+	}
+	for i, line := range lines {
+		isJSON := json.Valid([]byte(line))
+		if wantJSON := i >= 2; isJSON != wantJSON {
+			t.Errorf("line %d %q: JSON=%v; want %v", i, line, isJSON, wantJSON)
+		}
+	}
+}
+
+// 测试多协程并发写日志、修改等级与重新配置时没有数据竞争（配合 go test -race）
+func TestConcurrentLoggingRace(t *testing.T) {
+	testConcurrentLogging(t, Config{MinLevel: DEBUG})
+}
+
+// 同上，覆盖 Synchronous 模式下调用方协程直接写出的路径
+func TestConcurrentLoggingRaceSync(t *testing.T) {
+	testConcurrentLogging(t, Config{Synchronous: true, MinLevel: DEBUG})
+}
+
+func testConcurrentLogging(t *testing.T, cfg Config) {
+	t.Helper()
+	log, buf := newBufferLogger(t, cfg)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				log.Infow("concurrent", "g", g, "i", i)
+				_ = log.GetLevel()
+			}
+		}(g)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			log.SetLevel(DEBUG)
+			log.SetLevel(INFO)
+		}
+	}()
+	wg.Wait()
+
+	log.SetLevel(WARN)
+	if got := log.GetLevel(); got != WARN {
+		t.Errorf("GetLevel = %v; want WARN", got)
+	}
+	log.Info("filtered")
+	log.Close()
+
+	if out := buf.String(); strings.Count(out, "concurrent") != 800 || strings.Contains(out, "filtered") {
+		t.Errorf("unexpected output: %d concurrent lines, filtered present=%v",
+			strings.Count(out, "concurrent"), strings.Contains(out, "filtered"))
+	}
+}
+
+// 测试自定义 JSON 字段名
+func TestJSONKeys(t *testing.T) {
+	log := &Logger{core: &core{config: Config{
+		Format: FormatJSON,
+		JSONKeys: JSONKeys{
+			Time:      "@timestamp",
+			Level:     "severity",
+			Message:   "msg",
+			Caller:    "logger",
+			Component: "subsystem",
+		},
+	}}}
+	out := log.formatLog(logMsg{Level: WARN, Message: "hello", Caller: "main.go:1", Component: "db", Time: time.Now()})
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	want := map[string]interface{}{
+		"severity":  "WARN",
+		"msg":       "hello",
+		"logger":    "main.go:1",
+		"subsystem": "db",
+	}
+	for k, v := range want {
+		if data[k] != v {
+			t.Errorf("key %q = %v; want %v", k, data[k], v)
+		}
+	}
+	if _, ok := data["@timestamp"]; !ok {
+		t.Errorf("missing @timestamp in %s", out)
+	}
+	for _, k := range []string{"time", "level", "message", "caller", "component"} {
+		if _, ok := data[k]; ok {
+			t.Errorf("default key %q should be renamed in %s", k, out)
+		}
+	}
+}
+
+// here 返回调用处的行号，用于与日志中的 caller 比对
+func here() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+// 测试各种日志入口的 caller 都指向用户调用处
+func TestCallerAcrossEntryPoints(t *testing.T) {
+	cases := []struct {
+		name string
+		emit func(l *Logger) int
+	}{
+		{"plain", func(l *Logger) int {
+			line := here() + 1
+			l.Info("plain")
+			return line
+		}},
+		{"sugared", func(l *Logger) int {
+			line := here() + 1
+			l.Infow("sugared", "k", "v")
+			return line
+		}},
+		{"ctx", func(l *Logger) int {
+			line := here() + 1
+			l.InfoCtx(context.Background(), "ctx", "k", "v")
+			return line
+		}},
+		{"named", func(l *Logger) int {
+			line := here() + 1
+			l.Named("sub").Warn("named")
+			return line
+		}},
+		{"panic", func(l *Logger) (line int) {
+			defer func() { l.logPanic(recover(), 2) }()
+			line = here() + 1
+			panic("boom")
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG})
+			line := c.emit(log)
+			log.Close()
+
+			want := fmt.Sprintf("logger_test.go:%d ", line)
+			if out := buf.String(); !strings.Contains(out, want) {
+				t.Errorf("output %q does not contain caller %q", out, want)
+			}
+		})
+	}
+}
+
+// 测试 LogConfigOnStart 输出的第一行包含生效的配置
+func TestLogConfigOnStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "banner.log")
+	log := New(Config{
+		MinLevel:         WARN,
+		Format:           FormatJSON,
+		Targets:          OutputFile,
+		LogPath:          path,
+		LogConfigOnStart: true,
+	})
+	log.Warn("after banner")
+	log.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected banner plus one line, got %q", data)
+	}
+	var banner map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &banner); err != nil {
+		t.Fatalf("banner is not JSON: %q", lines[0])
+	}
+	want := map[string]interface{}{
+		"level":     "INFO",
+		"min_level": "WARN",
+		"format":    "json",
+		"targets":   "file",
+		"log_path":  path,
+	}
+	for k, v := range want {
+		if banner[k] != v {
+			t.Errorf("banner[%q] = %v; want %v", k, banner[k], v)
+		}
+	}
+}
+
+// gateWriter 在 release 关闭前阻塞所有写入，用于让消费协程停顿
+type gateWriter struct {
+	syncBuffer
+	release chan struct{}
+}
+
+func newGateWriter() *gateWriter { return &gateWriter{release: make(chan struct{})} }
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.syncBuffer.Write(p)
+}
+
+// 测试 OverflowDrop 丢弃溢出的日志，并按间隔输出带正确条数的汇总
+func TestDropSummary(t *testing.T) {
+	log := New(Config{
+		MinLevel:           DEBUG,
+		Targets:            OutputFile,
+		LogPath:            filepath.Join(t.TempDir(), "drop.log"),
+		Overflow:           OverflowDrop,
+		DropReportInterval: 20 * time.Millisecond,
+	})
+	gate := newGateWriter()
+	log.fileLogger = gate
+
+	for i := 0; i < 1500; i++ {
+		log.Info("flood")
+	}
+	dropped := log.Stats().Dropped
+	if dropped == 0 {
+		t.Fatalf("expected some messages to be dropped")
+	}
+	close(gate.release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	want := fmt.Sprintf("dropped %d messages in the last interval", dropped)
+	for !strings.Contains(gate.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("summary %q not found in output", want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	log.Close()
+
+	if n := strings.Count(gate.String(), "flood"); uint64(n)+dropped != 1500 {
+		t.Errorf("written %d + dropped %d != 1500", n, dropped)
+	}
+	if strings.Count(gate.String(), "dropped ") != 1 {
+		t.Errorf("expected exactly one summary line")
+	}
+}
+
+// 测试 IncludeCaller=false 时两种格式都不含 caller
+func TestIncludeCallerDisabled(t *testing.T) {
+	off := false
+	log, capture := NewCapturing()
+	defer log.Close()
+	if err := log.Reconfigure(Config{MinLevel: TRACE, IncludeCaller: &off}); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	log.Info("no caller")
+	rec := capture.Lines()[0]
+	if rec.Caller != "" {
+		t.Fatalf("Caller = %q; want empty", rec.Caller)
+	}
+
+	msg := logMsg{Level: rec.Level, Message: rec.Message, Time: rec.Time, Caller: rec.Caller}
+	jsonOut := (&Logger{core: &core{config: Config{Format: FormatJSON}}}).formatLog(msg)
+	if strings.Contains(jsonOut, `"caller"`) {
+		t.Errorf("JSON output should omit caller: %s", jsonOut)
+	}
+	plain := (&Logger{core: &core{config: Config{Format: FormatPlain}}}).formatLog(msg)
+	if want := fmt.Sprintf("[INFO] %s no caller\n", rec.Time.Format("2006-01-02 15:04:05")); plain != want {
+		t.Errorf("plain output = %q; want %q", plain, want)
+	}
+}
+
+func benchmarkCaller(b *testing.B, include bool) {
+	log := New(Config{MinLevel: INFO, Targets: OutputNone, IncludeCaller: &include})
+	defer log.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("bench")
+	}
+}
+
+func BenchmarkLogWithCaller(b *testing.B)    { benchmarkCaller(b, true) }
+func BenchmarkLogWithoutCaller(b *testing.B) { benchmarkCaller(b, false) }
+
+// 测试 OutputTarget 与名称列表之间的 JSON 往返
+func TestOutputTargetJSON(t *testing.T) {
+	cases := []struct {
+		target OutputTarget
+		json   string
+	}{
+		{OutputNone, `[]`},
+		{OutputConsole, `["console"]`},
+		{OutputFile, `["file"]`},
+		{OutputConsole | OutputFile, `["console","file"]`},
+	}
+	for _, c := range cases {
+		b, err := json.Marshal(c.target)
+		if err != nil || string(b) != c.json {
+			t.Errorf("Marshal(%v) = %s, %v; want %s", c.target, b, err, c.json)
+		}
+		var got OutputTarget
+		if err := json.Unmarshal(b, &got); err != nil || got != c.target {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v", b, got, err, c.target)
+		}
+	}
+
+	var got OutputTarget
+	if err := json.Unmarshal([]byte(`["file","console","file"]`), &got); err != nil || got != OutputConsole|OutputFile {
+		t.Errorf("duplicate names = %v, %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(`["console","syslog"]`), &got); err == nil {
+		t.Errorf("expected error for unknown target name")
+	}
+}
+
+// 测试 SplitCaller 时 JSON 中 caller 拆成 file、line、func 三个字段
+func TestSplitCaller(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, Format: FormatJSON, SplitCaller: true})
+	line := here() + 1
+	log.Info("split")
+	log.Close()
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(buf.String()), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if data["file"] != "logger_test.go" {
+		t.Errorf("file = %v, want logger_test.go", data["file"])
+	}
+	if data["line"] != float64(line) {
+		t.Errorf("line = %v, want %d", data["line"], line)
+	}
+	if data["func"] != "logger.TestSplitCaller" {
+		t.Errorf("func = %v, want logger.TestSplitCaller", data["func"])
+	}
+	if _, ok := data["caller"]; ok {
+		t.Errorf("caller should be omitted when SplitCaller is set: %s", buf.String())
+	}
+}
+
+// syncCountWriter 记录每次 Sync 时已写入的内容
+type syncCountWriter struct {
+	syncBuffer
+	syncMu sync.Mutex
+	synced []string
+}
+
+func (w *syncCountWriter) Sync() error {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	w.synced = append(w.synced, w.String())
+	return nil
+}
+
+func (w *syncCountWriter) Synced() []string {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	return append([]string(nil), w.synced...)
+}
+
+// 测试 SyncOnError 只在写出 ERROR 后同步文件
+func TestSyncOnError(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO, SyncOnError: true})
+	w := &syncCountWriter{}
+	log.fileLogger = w
+	log.Info("buffered")
+	log.Flush()
+	if len(w.synced) != 0 {
+		t.Fatalf("INFO triggered %d syncs; want 0", len(w.synced))
+	}
+	log.Error("durable")
+	log.Close()
+	if len(w.synced) != 1 || !strings.Contains(w.synced[0], "durable") {
+		t.Errorf("synced = %q; want one sync containing the ERROR line", w.synced)
+	}
+
+	// lumberjack 写入器通过另开句柄同步
+	path := filepath.Join(t.TempDir(), "sync.log")
+	lj := newFileWriter(path, RotateConfig{})
+	defer lj.Close()
+	if _, err := lj.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncWriter(lj); err != nil {
+		t.Errorf("syncWriter(lumberjack) = %v", err)
+	}
+}
+
+func paymentsCharge(log *Logger)     { log.Debug("payments debug") }
+func paymentsAuditCheck(log *Logger) { log.Info("audit info") }
+func ordersCreate(log *Logger)       { log.Debug("orders debug"); log.Info("orders info") }
+
+// 测试 PrefixLevels 按最长前缀选择最低等级，未匹配的调用方回退到 MinLevel
+func TestPrefixLevels(t *testing.T) {
+	const pkg = "github.com/xiangxu05/logger."
+	log, buf := newBufferLogger(t, Config{
+		MinLevel: INFO,
+		PrefixLevels: map[string]Level{
+			pkg + "payments":      DEBUG,
+			pkg + "paymentsAudit": WARN,
+		},
+	})
+	paymentsCharge(log)
+	paymentsAuditCheck(log)
+	ordersCreate(log)
+	log.Debugw("debug with fields", "k", 1)
+	log.Close()
+
+	out := buf.String()
+	for _, want := range []string{"payments debug", "orders info"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"audit info", "orders debug", "debug with fields"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output should not contain %q:\n%s", unwanted, out)
+		}
+	}
+}
+
+// waitLevel 轮询等待 GetLevel 变为 want
+func waitLevel(t *testing.T, log *Logger, want Level) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for log.GetLevel() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("level = %v; want %v", log.GetLevel(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// 测试 BoostLevel 到期自动恢复，重叠提升以最后一次为准，SetLevel 会取消提升
+func TestBoostLevel(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{MinLevel: INFO})
+	defer log.Close()
+
+	log.BoostLevel(DEBUG, 20*time.Millisecond)
+	if got := log.GetLevel(); got != DEBUG {
+		t.Fatalf("level after boost = %v; want DEBUG", got)
+	}
+	log.Debug("boosted debug")
+	waitLevel(t, log, INFO)
+	log.Debug("hidden debug")
+	log.Flush()
+	if out := buf.String(); !strings.Contains(out, "boosted debug") || strings.Contains(out, "hidden debug") {
+		t.Errorf("output = %q", out)
+	}
+
+	// 重叠提升：恢复为首次提升前的等级
+	log.BoostLevel(DEBUG, time.Hour)
+	log.BoostLevel(TRACE, 20*time.Millisecond)
+	if got := log.GetLevel(); got != TRACE {
+		t.Fatalf("level after second boost = %v; want TRACE", got)
+	}
+	waitLevel(t, log, INFO)
+
+	// SetLevel 取消尚未到期的提升
+	log.BoostLevel(DEBUG, 20*time.Millisecond)
+	log.SetLevel(WARN)
+	time.Sleep(50 * time.Millisecond)
+	if got := log.GetLevel(); got != WARN {
+		t.Errorf("level after SetLevel during boost = %v; want WARN", got)
+	}
+}
+
+// 测试嵌套 PushLevel 按后进先出恢复，乱序恢复与重复恢复也回到正确的等级，SetLevel 丢弃未恢复的层
+func TestPushLevel(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO})
+	defer log.Close()
+	expect := func(step string, want Level) {
+		t.Helper()
+		if got := log.GetLevel(); got != want {
+			t.Errorf("%s: level = %v; want %v", step, got, want)
+		}
+	}
+
+	outer := log.PushLevel(DEBUG)
+	inner := log.PushLevel(TRACE)
+	expect("inner push", TRACE)
+	inner()
+	expect("inner restore", DEBUG)
+	inner()
+	expect("repeated inner restore", DEBUG)
+	outer()
+	expect("outer restore", INFO)
+
+	// 外层先恢复：等级保持内层的，内层恢复后回到最外层之前
+	outer = log.PushLevel(WARN)
+	inner = log.PushLevel(ERROR)
+	outer()
+	expect("outer restore first", ERROR)
+	inner()
+	expect("inner restore last", INFO)
+
+	// 作用域内的 BoostLevel 到期后回到压入的等级，restore 取消它
+	restore := log.PushLevel(WARN)
+	log.BoostLevel(DEBUG, 20*time.Millisecond)
+	waitLevel(t, log, WARN)
+	log.BoostLevel(DEBUG, time.Hour)
+	restore()
+	expect("restore during boost", INFO)
+
+	restore = log.PushLevel(DEBUG)
+	log.SetLevel(ERROR)
+	restore()
+	expect("restore after SetLevel", ERROR)
+}
+
+// 测试 MaxMessageBytes 截断超长消息，截断点落在字符边界上且 JSON 仍然合法
+func TestMaxMessageBytes(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, Format: FormatJSON, MaxMessageBytes: 10})
+	log.Info("short")
+	log.Info(strings.Repeat("a", 8) + "日志" + strings.Repeat("b", 1000))
+	log.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %s", len(lines), buf.String())
+	}
+	var msgs []string
+	for _, line := range lines {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		msgs = append(msgs, data["message"].(string))
+	}
+	if msgs[0] != "short" {
+		t.Errorf("short message = %q", msgs[0])
+	}
+	// "日" 占 3 字节，第 10 字节落在字符中间，应退回到 8 字节处
+	want := "aaaaaaaa...[truncated 1006 bytes]"
+	if msgs[1] != want {
+		t.Errorf("truncated message = %q; want %q", msgs[1], want)
+	}
+}
+
+// disabledEntryPoints 在 MinLevel 为 ERROR 的 Logger 上调用每个会被过滤掉的日志入口。
+// 键值参数使用常量和已是接口的值：把非常量的具体类型值装箱成 interface{} 发生在调用方、
+// 早于任何等级判断，不属于日志库能消除的分配。
+func disabledEntryPoints(log *Logger, m *MultiLogger, err error) map[string]func() {
+	return map[string]func(){
+		"Debug":       func() { log.Debug("debug") },
+		"Info":        func() { log.Info("info") },
+		"Warn":        func() { log.Warn("warn") },
+		"Tracew":      func() { log.Tracew("trace", "n", 1000, "err", err) },
+		"Debugw":      func() { log.Debugw("debug", "n", 1000, "err", err) },
+		"Infow":       func() { log.Infow("info", "n", 1000, "err", err) },
+		"Warnw":       func() { log.Warnw("warn", "n", 1000, "err", err) },
+		"Multi.Info":  func() { m.Info("info") },
+		"Multi.Infow": func() { m.Infow("info", "n", 1000, "err", err) },
+	}
+}
+
+// 测试被等级过滤掉的日志调用不产生任何内存分配
+func TestDisabledLevelZeroAlloc(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: ERROR})
+	defer log.Close()
+	err := errors.New("boom")
+	for name, fn := range disabledEntryPoints(log, NewMulti(log), err) {
+		if allocs := testing.AllocsPerRun(100, fn); allocs != 0 {
+			t.Errorf("%s allocated %v times per call at a disabled level; want 0", name, allocs)
+		}
+	}
+}
+
+func BenchmarkDisabledLevel(b *testing.B) {
+	log := New(Config{MinLevel: ERROR, Targets: OutputNone})
+	defer log.Close()
+	err := errors.New("boom")
+	for name, fn := range disabledEntryPoints(log, NewMulti(log), err) {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				fn()
+			}
+		})
+	}
+}
+
+// 测试 FlushInterval 定时同步有新写入的文件，没有新写入时不重复同步
+func TestFlushInterval(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO, FlushInterval: 10 * time.Millisecond})
+	w := &syncCountWriter{}
+	log.fileLogger = w
+	defer log.Close()
+
+	log.Info("first")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if synced := w.Synced(); len(synced) > 0 {
+			if !strings.Contains(synced[0], "first") {
+				t.Fatalf("first sync = %q; want it to contain the logged line", synced[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("line was not synced within the flush interval")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 空闲期间不应再同步
+	time.Sleep(50 * time.Millisecond)
+	if n := len(w.Synced()); n != 1 {
+		t.Errorf("idle logger synced %d times; want 1", n)
+	}
+}
+
+func panicWithFrames(log *Logger) {
+	defer func() { log.logPanic(recover(), 2) }()
+	panic("structured boom")
+}
+
+// 测试 StructuredPanic 时 JSON 中 panic 为字符串、stack 为以发生 panic 的函数开头的帧数组
+func TestStructuredPanic(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG, Format: FormatJSON, StructuredPanic: true})
+	panicWithFrames(log)
+	log.Close()
+
+	var data struct {
+		Message string
+		Panic   string
+		Stack   []struct {
+			File     string
+			Line     int
+			Function string
+		}
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if data.Message != "Panic recovered" || data.Panic != "structured boom" {
+		t.Errorf("message = %q, panic = %q", data.Message, data.Panic)
+	}
+	if len(data.Stack) < 2 {
+		t.Fatalf("stack = %+v; want at least two frames", data.Stack)
+	}
+	top := data.Stack[0]
+	if top.Function != "github.com/xiangxu05/logger.panicWithFrames" ||
+		!strings.HasSuffix(top.File, "logger_test.go") || top.Line == 0 {
+		t.Errorf("top frame = %+v; want panicWithFrames in logger_test.go", top)
+	}
+	if data.Stack[1].Function != "github.com/xiangxu05/logger.TestStructuredPanic" {
+		t.Errorf("second frame = %+v; want TestStructuredPanic", data.Stack[1])
+	}
+
+	// plain 格式保留文本栈
+	log, buf = newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG, StructuredPanic: true})
+	panicWithFrames(log)
+	log.Close()
+	if out := buf.String(); !strings.Contains(out, "Panic recovered: structured boom\ngoroutine ") {
+		t.Errorf("plain panic output = %q", out)
+	}
+}
+
+// 测试 StructuredPanic 按各目标自己的格式选择：JSON 文件得到可解析的单行 JSON，plain 控制台保留文本栈
+func TestStructuredPanicPerTargetFormat(t *testing.T) {
+	fileFormat := FormatJSON
+	console := &syncBuffer{}
+	log := New(Config{
+		Synchronous:     true,
+		MinLevel:        DEBUG,
+		Targets:         OutputConsole | OutputFile,
+		ConsoleWriter:   console,
+		LogPath:         filepath.Join(t.TempDir(), "test.log"),
+		FileFormat:      &fileFormat,
+		StructuredPanic: true,
+	})
+	file := &syncBuffer{}
+	log.fileLogger = file
+	panicWithFrames(log)
+	log.Close()
+
+	lines := strings.Split(strings.TrimSuffix(file.String(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("file output has %d lines; want one JSON record: %q", len(lines), file.String())
+	}
+	var data struct {
+		Panic string
+		Stack []struct{ Function string }
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[0], err)
+	}
+	if data.Panic != "structured boom" || len(data.Stack) == 0 {
+		t.Errorf("panic = %q, stack = %+v", data.Panic, data.Stack)
+	}
+	if out := console.String(); !strings.Contains(out, "Panic recovered: structured boom\ngoroutine ") {
+		t.Errorf("console panic output = %q; want the text stack", out)
+	}
+}
+
+func panicWithSource(log *Logger, opts PanicOptions) {
+	defer func() { log.logPanicWith(recover(), 2, opts) }()
+	panic("snippet boom") // source snippet marker
+}
+
+// 测试 SourcePrefixes 为本项目的帧附加源代码行，不匹配或读不到的文件跳过
+func TestPanicSourceSnippet(t *testing.T) {
+	_, self, _, _ := runtime.Caller(0)
+	opts := PanicOptions{SourcePrefixes: []string{filepath.Dir(self)}}
+	const want = `panic("snippet boom") // source snippet marker`
+
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG})
+	panicWithSource(log, opts)
+	log.Close()
+	out := buf.String()
+	if !strings.Contains(out, "logger_test.go:") || !strings.Contains(out, "\t\t> "+want+"\n") {
+		t.Errorf("plain panic output lacks the source line:\n%s", out)
+	}
+	lines := strings.Split(out, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "\t\t> ") && !strings.HasPrefix(lines[i-1], "\t"+filepath.Dir(self)) {
+			t.Errorf("source line %q follows frame %q outside SourcePrefixes", lines[i], lines[i-1])
+		}
+	}
+
+	log, buf = newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG, Format: FormatJSON, StructuredPanic: true})
+	panicWithSource(log, opts)
+	log.Close()
+	var data struct{ Stack []stackFrame }
+	if err := json.Unmarshal([]byte(buf.String()), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(data.Stack) == 0 || data.Stack[0].Source != want {
+		t.Errorf("top frame = %+v; want source %q", data.Stack, want)
+	}
+
+	// 读不到的文件原样保留
+	stack := []byte("main.f()\n\t" + filepath.Dir(self) + "/missing.go:3 +0x1d\n")
+	if got := opts.annotateStack(stack); !bytes.Equal(got, stack) {
+		t.Errorf("annotateStack with unreadable file = %q; want it unchanged", got)
+	}
+}
+
+// 测试 logPanicRethrow 在写出并同步 panic 日志（及此前入队的日志）之后以原值重新 panic
+func TestRecoverAndLogPanicRethrow(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO})
+	defer log.Close()
+	w := &syncCountWriter{}
+	log.fileLogger = w
+
+	type crash struct{ code int }
+	var got interface{}
+	func() {
+		defer func() { got = recover() }()
+		func() {
+			defer func() { log.logPanicRethrow(recover(), 2) }()
+			log.Info("before crash")
+			panic(crash{code: 7})
+		}()
+	}()
+
+	if got != (crash{code: 7}) {
+		t.Fatalf("recovered %v; want the original panic value", got)
+	}
+	synced := w.Synced()
+	if len(synced) == 0 {
+		t.Fatal("files were not synced before re-panicking")
+	}
+	last := synced[len(synced)-1]
+	if !strings.Contains(last, "before crash") || !strings.Contains(last, "Panic recovered: {7}") {
+		t.Errorf("synced content = %q; want the queued line and the panic", last)
+	}
+}
+
+// 测试 OutputTarget 的取值固定为连续的 2 的幂，组合按位或
+func TestOutputTargetValues(t *testing.T) {
+	values := []struct {
+		target OutputTarget
+		want   int
+	}{
+		{OutputNone, 0},
+		{OutputConsole, 1},
+		{OutputFile, 2},
+		{OutputEventLog, 4},
+		{OutputOSLog, 8},
+	}
+	for _, v := range values {
+		if int(v.target) != v.want {
+			t.Errorf("%v = %d; want %d", v.target, int(v.target), v.want)
+		}
+	}
+	both := OutputConsole | OutputFile
+	if int(both) != 3 || both&OutputConsole == 0 || both&OutputFile == 0 || both&OutputEventLog != 0 {
+		t.Errorf("OutputConsole|OutputFile = %d (%v)", int(both), both)
+	}
+	if got := (OutputConsole | OutputFile | OutputEventLog).String(); got != "console|file|eventlog" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+// 测试 Enabled 与 MinLevel、SetLevel 一致，NewNop 始终返回 false
+func TestEnabled(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO})
+	defer log.Close()
+	if log.Enabled(DEBUG) || !log.Enabled(INFO) || !log.Enabled(ERROR) {
+		t.Errorf("Enabled at INFO = %v,%v,%v", log.Enabled(DEBUG), log.Enabled(INFO), log.Enabled(ERROR))
+	}
+	log.SetLevel(TRACE)
+	if !log.Enabled(TRACE) {
+		t.Errorf("Enabled(TRACE) after SetLevel(TRACE) = false")
+	}
+	if NewNop().Enabled(ERROR) {
+		t.Errorf("nop logger reports ERROR as enabled")
+	}
+}
+
+// 测试 ConsoleWriter 接收所有控制台输出，包括带颜色的普通日志与 panic 日志
+func TestConsoleWriter(t *testing.T) {
+	console := &syncBuffer{}
+	log := New(Config{MinLevel: INFO, Targets: OutputConsole, ConsoleWriter: console, ErrorsToStderr: true})
+	log.Warn("to console writer")
+	func() {
+		defer func() { log.logPanic(recover(), 2) }()
+		panic("console panic")
+	}()
+	log.Close()
+
+	out := console.String()
+	if !strings.Contains(out, defaultLevelColors[WARN]+"[WARN]") || !strings.Contains(out, "to console writer\n\033[0m") {
+		t.Errorf("colored WARN line missing from console writer: %q", out)
+	}
+	if !strings.Contains(out, defaultLevelColors[ERROR]+"[ERROR]") || !strings.Contains(out, "Panic recovered: console panic") {
+		t.Errorf("colored panic output missing from console writer: %q", out)
+	}
+}
+
+// 测试文件被外部移走后，Reopen 让之后的日志写入配置路径上的新文件
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log := New(Config{MinLevel: INFO, Targets: OutputFile, LogPath: path})
+	log.Info("before rotate")
+	log.Flush()
+
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("still old handle")
+	if err := log.Reopen(); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	log.Info("after reopen")
+	log.Close()
+
+	oldData, _ := os.ReadFile(rotated)
+	newData, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("new log file was not created: %v", err)
+	}
+	if old := string(oldData); !strings.Contains(old, "before rotate") || !strings.Contains(old, "still old handle") {
+		t.Errorf("rotated file = %q", old)
+	}
+	if fresh := string(newData); !strings.Contains(fresh, "after reopen") || strings.Contains(fresh, "before rotate") {
+		t.Errorf("fresh file = %q", fresh)
+	}
+	if err := log.Reopen(); err != ErrClosed {
+		t.Errorf("Reopen after Close = %v; want ErrClosed", err)
+	}
+}
+
+// 测试 Fatal/FatalCode 在退出前已把 FATAL 日志写入文件，并传递对应的退出码
+func TestFatalCode(t *testing.T) {
+	defer func() { exit = os.Exit }()
+	for _, tc := range []struct {
+		name string
+		call func(*Logger)
+		code int
+	}{
+		{"Fatal", func(l *Logger) { l.Fatal("fatal default") }, 1},
+		{"FatalCode", func(l *Logger) { l.FatalCode(3, "fatal custom") }, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			log := New(Config{MinLevel: INFO, Targets: OutputFile, LogPath: path})
+			code := -1
+			var atExit string
+			exit = func(c int) {
+				code = c
+				data, _ := os.ReadFile(path)
+				atExit = string(data)
+			}
+			tc.call(log)
+			if code != tc.code {
+				t.Errorf("exit code = %d; want %d", code, tc.code)
+			}
+			if !strings.Contains(atExit, "[FATAL]") || !strings.Contains(atExit, "fatal ") {
+				t.Errorf("file at exit = %q; want the FATAL line already written", atExit)
+			}
+		})
+	}
+}
+
+// 测试 HeartbeatInterval 定期输出心跳日志，且不受 MinLevel 限制
+func TestHeartbeat(t *testing.T) {
+	// 心跳可能在第一次 tick 前就写出，这里用 ConsoleWriter 而不是替换 fileLogger
+	buf := &syncBuffer{}
+	log := New(Config{
+		MinLevel:          WARN,
+		Format:            FormatJSON,
+		Targets:           OutputConsole,
+		ConsoleWriter:     buf,
+		HeartbeatInterval: 5 * time.Millisecond,
+	})
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), `"message":"heartbeat"`) {
+		if time.Now().After(deadline) {
+			t.Fatal("no heartbeat within 2s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	log.Close()
+
+	line := strings.SplitN(buf.String(), "\n", 2)[0]
+	line = line[strings.IndexByte(line, '{') : strings.LastIndexByte(line, '}')+1] // 去掉控制台颜色
+	var rec map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		t.Fatalf("heartbeat line %q: %v", line, err)
+	}
+	if rec["level"] != "INFO" {
+		t.Errorf("heartbeat level = %v; want INFO", rec["level"])
+	}
+	for _, key := range []string{"goroutines", "heap_alloc", "num_gc"} {
+		if _, ok := rec[key].(float64); !ok {
+			t.Errorf("heartbeat %s = %v; want a number", key, rec[key])
+		}
+	}
+	if rec["goroutines"].(float64) < 1 {
+		t.Errorf("goroutines = %v; want >= 1", rec["goroutines"])
+	}
+}
+
+// 测试 AllowedMinLevel 使白名单文件只接收不低于该等级的日志，主日志不受影响
+func TestAllowedMinLevel(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{
+		MinLevel:        INFO,
+		AllowedPrefix:   []string{"logger_test.go"},
+		AllowedMinLevel: ERROR,
+	})
+	allowed := &syncBuffer{}
+	log.allowFileLogger = allowed
+	log.Info("whitelisted info")
+	log.Error("whitelisted error")
+	log.Close()
+
+	if out := allowed.String(); strings.Contains(out, "whitelisted info") || !strings.Contains(out, "whitelisted error") {
+		t.Errorf("allowed file = %q; want only the ERROR line", out)
+	}
+	if out := buf.String(); !strings.Contains(out, "whitelisted info") || !strings.Contains(out, "whitelisted error") {
+		t.Errorf("main file = %q; want both lines", out)
+	}
+}
+
+// 测试 Config 返回包含默认值的生效配置副本，修改副本不影响 Logger，且反映 SetLevel/Reconfigure
+func TestConfig(t *testing.T) {
+	keep := false
+	path := filepath.Join(t.TempDir(), "app.log")
+	log := New(Config{
+		MinLevel:      DEBUG,
+		Format:        FormatJSON,
+		Targets:       OutputFile,
+		LogPath:       path,
+		MaskKeys:      []string{"token"},
+		FileRotation:  RotateConfig{MaxSize: 50},
+		IncludeCaller: &keep,
+	})
+	defer log.Close()
+
+	cfg := log.Config()
+	if cfg.MinLevel != DEBUG || cfg.Format != FormatJSON || cfg.LogPath != path {
+		t.Errorf("user values = %v/%v/%q; want DEBUG/json/%s", cfg.MinLevel, cfg.Format, cfg.LogPath, path)
+	}
+	if r := cfg.FileRotation; r.MaxSize != 50 || r.MaxBackups != 5 || r.MaxAge != 7 || r.Compress == nil || !*r.Compress {
+		t.Errorf("FileRotation = %+v; want MaxSize 50 with defaults", r)
+	}
+	if cfg.DropReportInterval != defaultDropReportInterval {
+		t.Errorf("DropReportInterval = %v; want %v", cfg.DropReportInterval, defaultDropReportInterval)
+	}
+	if cfg.IncludeCaller == nil || *cfg.IncludeCaller {
+		t.Errorf("IncludeCaller = %v; want false", cfg.IncludeCaller)
+	}
+
+	cfg.MaskKeys[0] = "changed"
+	*cfg.IncludeCaller = true
+	*cfg.FileRotation.Compress = false
+	if again := log.Config(); again.MaskKeys[0] != "token" || *again.IncludeCaller || !*again.FileRotation.Compress {
+		t.Errorf("mutating the copy changed the logger: %+v", again)
+	}
+
+	log.SetLevel(WARN)
+	if got := log.Config().MinLevel; got != WARN {
+		t.Errorf("MinLevel after SetLevel = %v; want WARN", got)
+	}
+	if err := log.Reconfigure(Config{MinLevel: ERROR, Targets: OutputNone}); err != nil {
+		t.Fatal(err)
+	}
+	if got := log.Config(); got.MinLevel != ERROR || got.Targets != OutputNone {
+		t.Errorf("after Reconfigure = %v/%v; want ERROR/none", got.MinLevel, got.Targets)
+	}
+
+	if got := NewNop().Config(); got.Targets != OutputNone {
+		t.Errorf("NewNop().Config().Targets = %v; want none", got.Targets)
+	}
+}
+
+// 测试日志调用与 Close 并发时不会 panic：每条日志要么写出、要么计为丢弃，写出的部分在各协程内保持顺序
+func TestConcurrentLogAndClose(t *testing.T) {
+	for _, cfg := range []Config{
+		{MinLevel: INFO, BufferSize: 8},
+		{MinLevel: INFO, BufferSize: 8, OverflowBufferSize: 32},
+		{MinLevel: INFO, BufferSize: 8, Overflow: OverflowDrop},
+	} {
+		log, buf := newBufferLogger(t, cfg)
+		const producers, perProducer = 8, 300
+		var wg sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; i < perProducer; i++ {
+					log.Infow("hammer", "p", p, "i", i)
+				}
+			}(p)
+		}
+		time.Sleep(time.Millisecond)
+		log.Close()
+		wg.Wait()
+
+		last := make(map[string]int)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		for _, line := range lines {
+			if !strings.Contains(line, "hammer") {
+				continue // OverflowDrop 关闭时的丢弃汇总
+			}
+			var p string
+			var i int
+			if _, err := fmt.Sscanf(line[strings.Index(line, "p="):], "p=%s i=%d", &p, &i); err != nil {
+				t.Fatalf("unexpected line %q: %v", line, err)
+			}
+			if prev, ok := last[p]; ok && i <= prev {
+				t.Fatalf("producer %s wrote %d after %d", p, i, prev)
+			}
+			last[p] = i
+		}
+		written := uint64(strings.Count(buf.String(), "hammer"))
+		if stats := log.Stats(); written+stats.Dropped != producers*perProducer {
+			t.Errorf("%+v: written %d + dropped %d != %d", cfg, written, stats.Dropped, producers*perProducer)
+		}
+	}
+}
+
+// 测试 JSONLPath 总是写入 JSON 行，控制台与主日志文件仍使用配置的格式
+func TestJSONLPath(t *testing.T) {
+	dir := t.TempDir()
+	console := &syncBuffer{}
+	log := New(Config{
+		MinLevel:      INFO,
+		Format:        FormatPlain,
+		Targets:       OutputConsole | OutputFile,
+		ConsoleWriter: console,
+		LogPath:       filepath.Join(dir, "app.log"),
+		JSONLPath:     filepath.Join(dir, "jsonl", "app.jsonl"),
+	})
+	log.Infow("order created", "id", 7)
+	log.Close()
+
+	main, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, out := range map[string]string{"console": console.String(), "main file": string(main)} {
+		if !strings.Contains(out, "[INFO]") || !strings.Contains(out, "order created id=7") {
+			t.Errorf("%s = %q; want plain output", name, out)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "jsonl", "app.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec map[string]interface{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("jsonl line %q: %v", data, err)
+	}
+	if rec["message"] != "order created" || rec["id"] != float64(7) || rec["level"] != "INFO" {
+		t.Errorf("jsonl record = %v", rec)
+	}
+}
+
+// 测试设置了 Formatter 时 JSONLPath 仍写入内置 JSON，主日志文件使用 Formatter 的输出
+func TestJSONLPathIgnoresFormatter(t *testing.T) {
+	dir := t.TempDir()
+	log := New(Config{
+		MinLevel:  INFO,
+		Targets:   OutputFile,
+		LogPath:   filepath.Join(dir, "app.log"),
+		JSONLPath: filepath.Join(dir, "app.jsonl"),
+		Formatter: func(r LogRecord) string { return "custom|" + r.Message + "\n" },
+	})
+	log.Infow("first", "id", 1)
+	log.Warn("second")
+	func() {
+		defer func() { log.logPanic(recover(), 2) }()
+		panic("third")
+	}()
+	log.Close()
+
+	if main, _ := os.ReadFile(filepath.Join(dir, "app.log")); !strings.Contains(string(main), "custom|first\n") {
+		t.Errorf("main file = %q; want Formatter output", main)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "app.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("jsonl has %d lines; want 3: %q", len(lines), data)
+	}
+	for _, line := range lines {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Errorf("jsonl line %q: %v", line, err)
+		}
+	}
+}
+
+func panicInWorker() { panic("worker failed") }
+
+// 测试 Go 启动的协程发生 panic 时被记录（含 caller 与栈）且进程继续运行
+func TestGoRecoversPanic(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{MinLevel: INFO})
+	defer log.Close()
+
+	log.Go(panicInWorker)
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "Panic recovered: worker failed") {
+		if time.Now().After(deadline) {
+			t.Fatalf("panic not logged; output = %q", buf.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	out := buf.String()
+	if first := strings.SplitN(out, "\n", 2)[0]; !strings.Contains(first, "logger.panicInWorker") || !strings.Contains(out, "goroutine ") {
+		t.Errorf("panic log = %q; want caller panicInWorker and a stack", out)
+	}
+
+	done := make(chan struct{})
+	log.Go(func() { close(done) })
+	<-done
+}
+
+func nestedPanic(depth int) {
+	if depth == 0 {
+		panic("deep")
+	}
+	nestedPanic(depth - 1)
+}
+
+// 测试深层调用栈的 panic 日志不会被缓冲区截断：栈底的测试函数与 created by 行都应保留
+func TestPanicStackSize(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{MinLevel: INFO, PanicStackSize: 1024})
+	func() {
+		defer func() { log.logPanic(recover(), 2) }()
+		nestedPanic(200)
+	}()
+	log.Close()
+
+	out := buf.String()
+	if len(out) <= 1024 {
+		t.Fatalf("stack is %d bytes; want it to grow past the initial 1024", len(out))
+	}
+	for _, want := range []string{"logger.nestedPanic", "logger.TestPanicStackSize(", "created by testing."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("panic stack missing %q (truncated?):\n%s", want, out)
+		}
+	}
+}
+
+// 测试 Closed 在 Close 之前不就绪，在排空写完之后才关闭
+func TestClosed(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO})
+	gate := newGateWriter()
+	log.fileLogger = gate
+	log.Info("pending")
+
+	select {
+	case <-log.Closed():
+		t.Fatal("Closed fired before Close")
+	default:
+	}
+	go log.Close()
+	select {
+	case <-log.Closed():
+		t.Fatal("Closed fired while a line was still being written")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(gate.release)
+	select {
+	case <-log.Closed():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Closed did not fire after the drain completed")
+	}
+	if !strings.Contains(gate.String(), "pending") {
+		t.Errorf("output = %q; want the pending line written before Closed", gate.String())
+	}
+	<-NewNop().Closed()
+}
+
+type auditHandler struct{ log *Logger }
+
+func (h auditHandler) ServeHTTP()   { h.log.Info("from ServeHTTP") }
+func (h auditHandler) ServeHealth() { h.log.Info("from ServeHealth") }
+
+// 测试 AllowedMatch 只按函数名或只按文件名匹配白名单
+func TestAllowedMatch(t *testing.T) {
+	for _, tc := range []struct {
+		match  MatchField
+		prefix string
+		want   []string
+	}{
+		{MatchFunc, "ServeHTTP", []string{"from ServeHTTP"}},
+		{MatchFunc, "logger.auditHandler.Serve", []string{"from ServeHTTP", "from ServeHealth"}},
+		{MatchFunc, "logger_test", nil}, // 文件名不参与按函数名匹配
+		{MatchFile, "logger_test", []string{"from ServeHTTP", "from ServeHealth"}},
+		{MatchFile, "ServeHTTP", nil},
+	} {
+		log, _ := newBufferLogger(t, Config{MinLevel: INFO, AllowedPrefix: []string{tc.prefix}, AllowedMatch: tc.match})
+		allowed := &syncBuffer{}
+		log.allowFileLogger = allowed
+		h := auditHandler{log: log}
+		h.ServeHTTP()
+		h.ServeHealth()
+		log.Close()
+
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(allowed.String()), "\n") {
+			if i := strings.Index(line, "from "); i >= 0 {
+				got = append(got, line[i:])
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("match %d prefix %q: allowed = %q; want %q", tc.match, tc.prefix, got, tc.want)
+		}
+	}
+}
+
+// 测试运行中添加的前缀只影响之后的日志，删除后不再写入白名单文件
+func TestAddRemoveAllowedPrefix(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO, AllowedPrefix: []string{"ServeHealth"}, AllowedMatch: MatchFunc})
+	allowed := &syncBuffer{}
+	log.allowFileLogger = allowed
+	h := auditHandler{log: log}
+	h.ServeHTTP()
+	log.AddAllowedPrefix("ServeHTTP")
+	log.AddAllowedPrefix("ServeHTTP")
+	h.ServeHTTP()
+	log.RemoveAllowedPrefix("ServeHTTP")
+	h.ServeHTTP()
+	h.ServeHealth()
+	log.Close()
+
+	if n := strings.Count(allowed.String(), "from ServeHTTP"); n != 1 {
+		t.Errorf("allowed file has %d ServeHTTP lines; want only the one logged while the prefix was added: %q", n, allowed.String())
+	}
+	if !strings.Contains(allowed.String(), "from ServeHealth") {
+		t.Errorf("allowed file lost the configured prefix: %q", allowed.String())
+	}
+	if got := log.Config().AllowedPrefix; len(got) != 1 || got[0] != "ServeHealth" {
+		t.Errorf("AllowedPrefix = %q; want [ServeHealth]", got)
+	}
+}
+
+// 测试 AllowedToConsole 只给白名单日志加控制台标记；控制台不是输出目标时只有白名单日志出现在控制台
+func TestAllowedToConsole(t *testing.T) {
+	for _, withConsole := range []bool{true, false} {
+		console := &syncBuffer{}
+		log, _ := newBufferLogger(t, Config{
+			Synchronous:      true,
+			MinLevel:         INFO,
+			AllowedPrefix:    []string{"ServeHTTP"},
+			AllowedMatch:     MatchFunc,
+			AllowedToConsole: true,
+			ConsoleWriter:    console,
+		})
+		log.allowFileLogger = &syncBuffer{}
+		if withConsole {
+			log.config.Targets |= OutputConsole
+		}
+		h := auditHandler{log: log}
+		h.ServeHTTP()
+		h.ServeHealth()
+		log.Close()
+
+		var lines []string
+		for _, line := range strings.Split(console.String(), "\n") {
+			if strings.Contains(line, "from ") {
+				lines = append(lines, line)
+			}
+		}
+		want := 1
+		if withConsole {
+			want = 2
+		}
+		if len(lines) != want {
+			t.Fatalf("console=%v: got %d console lines; want %d:\n%s", withConsole, len(lines), want, console.String())
+		}
+		for _, line := range lines {
+			marked := strings.Contains(line, allowedConsoleMarker)
+			if strings.Contains(line, "from ServeHTTP") != marked {
+				t.Errorf("console=%v: line %q marked=%v", withConsole, line, marked)
+			}
+		}
+	}
+}
+
+// 测试同步模式下日志调用返回时即已写出，Reconfigure 与 Close 照常工作
+func TestSynchronous(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO})
+	log.Info("inline line")
+	if out := buf.String(); !strings.Contains(out, "inline line") {
+		t.Fatalf("output right after Info = %q; want the line", out)
+	}
+
+	cfg := log.Config()
+	cfg.Format = FormatJSON
+	cfg.Synchronous = false
+	if err := log.Reconfigure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !log.Config().Synchronous {
+		t.Error("Reconfigure switched off Synchronous; it should only apply at creation")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				log.Info("concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+	if n := strings.Count(buf.String(), `"message":"concurrent"`); n != 400 {
+		t.Errorf("got %d JSON lines; want 400", n)
+	}
+
+	log.Close()
+	log.Close()
+	select {
+	case <-log.Closed():
+	default:
+		t.Fatal("Closed() not closed after Close")
+	}
+	dropped := log.Stats().Dropped
+	log.Info("after close")
+	if got := log.Stats().Dropped; got != dropped+1 {
+		t.Errorf("Dropped = %d; want %d", got, dropped+1)
+	}
+	if err := log.Reopen(); err != ErrClosed {
+		t.Errorf("Reopen after Close = %v; want ErrClosed", err)
+	}
+}
+
+// 测试消费协程停顿时 Stats 报告的排队耗时随之升高
+func TestQueueLatency(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO})
+	defer log.Close()
+	log.Info("warm up")
+	log.Flush()
+	before := log.Stats()
+
+	gate := newGateWriter()
+	log.fileLogger = gate
+	for i := 0; i < 5; i++ {
+		log.Info("stalled")
+	}
+	const stall = 30 * time.Millisecond
+	time.Sleep(stall)
+	close(gate.release)
+	log.Flush()
+
+	after := log.Stats()
+	if after.QueueLatencyMax < stall || after.QueueLatencyMax <= before.QueueLatencyMax {
+		t.Errorf("QueueLatencyMax = %v (before %v); want at least %v", after.QueueLatencyMax, before.QueueLatencyMax, stall)
+	}
+	if after.QueueLatencyAvg <= before.QueueLatencyAvg {
+		t.Errorf("QueueLatencyAvg = %v; want it to rise above %v", after.QueueLatencyAvg, before.QueueLatencyAvg)
+	}
+}
+
+// failingWriter 模拟不可用的文件系统，每次写入都返回错误
+type failingWriter struct {
+	writes atomic.Int32
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes.Add(1)
+	return 0, errors.New("read-only file system")
+}
+
+func (w *failingWriter) Close() error { return nil }
+
+// 测试日志文件持续写入失败时改写到 stderr，且说明原因的 WARN 只输出一次
+func TestFileWriteFailureFallback(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO})
+	fw := &failingWriter{}
+	stderr := &syncBuffer{}
+	log.fileLogger = fw
+	log.stderr = stderr
+	for i := 0; i < 10; i++ {
+		log.Info(fmt.Sprintf("event %d", i))
+	}
+	log.Close()
+
+	out := stderr.String()
+	for i := 0; i < 10; i++ {
+		if !strings.Contains(out, fmt.Sprintf("event %d\n", i)) {
+			t.Errorf("stderr missing event %d: %q", i, out)
+		}
+	}
+	if n := strings.Count(out, "log file is not writable, falling back to stderr: "); n != 1 {
+		t.Errorf("fallback warning emitted %d times; want 1: %q", n, out)
+	}
+	if !strings.Contains(out, "read-only file system") {
+		t.Errorf("fallback warning lacks the write error: %q", out)
+	}
+	if n := fw.writes.Load(); n != fileFailureThreshold {
+		t.Errorf("file writes attempted = %d; want %d", n, fileFailureThreshold)
+	}
+	if strings.Contains(out, "\033[") {
+		t.Errorf("stderr fallback contains color codes: %q", out)
+	}
+
+	// 控制台也是输出目标时，日志只在控制台出现一次，不再复制到 stderr
+	log, _ = newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO})
+	console := &syncBuffer{}
+	stderr = &syncBuffer{}
+	log.fileLogger = &failingWriter{}
+	log.stderr = stderr
+	log.config.Targets |= OutputConsole
+	log.config.ConsoleWriter = console
+	for i := 0; i < 5; i++ {
+		log.Info(fmt.Sprintf("event %d", i))
+	}
+	log.Close()
+	if stderr.String() != "" {
+		t.Errorf("stderr = %q; want nothing when the console already has the records", stderr.String())
+	}
+	if n := strings.Count(console.String(), "event 0"); n != 1 {
+		t.Errorf("console has event 0 %d times; want 1: %q", n, console.String())
+	}
+	if !strings.Contains(console.String(), "log file is not writable, record already on console, not duplicated to stderr") ||
+		strings.Contains(console.String(), "falling back to stderr") {
+		t.Errorf("console lacks the fallback warning: %q", console.String())
+	}
+}
+
+// 测试 SummaryOnClose 在关闭时写出按等级计数的汇总行，且位于全部日志之后
+func TestSummaryOnClose(t *testing.T) {
+	for _, synchronous := range []bool{false, true} {
+		log, buf := newBufferLogger(t, Config{MinLevel: DEBUG, SummaryOnClose: true, Synchronous: synchronous})
+		log.Trace("filtered")
+		for i := 0; i < 3; i++ {
+			log.Debug("d")
+		}
+		log.Info("i")
+		log.Warn("w")
+		log.Warn("w")
+		log.Error("e")
+		log.Close()
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		last := lines[len(lines)-1]
+		if want := "session summary: trace=0 debug=3 info=1 warn=2 error=1 fatal=0"; len(lines) != 8 || !strings.HasSuffix(last, want) {
+			t.Errorf("synchronous=%v: last of %d lines = %q; want suffix %q", synchronous, len(lines), last, want)
+		}
+	}
+
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO})
+	log.Info("i")
+	log.Close()
+	if strings.Contains(buf.String(), "session summary") {
+		t.Errorf("summary written without SummaryOnClose: %q", buf.String())
+	}
+}
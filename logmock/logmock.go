@@ -0,0 +1,96 @@
+// Package logmock 提供 logger.Interface 的记录型实现，供依赖 logger.Interface 的代码在单元测试中断言日志调用。
+// 只需丢弃日志时使用 logger.NewNop()。
+package logmock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/xiangxu05/logger"
+)
+
+// Call 是一次日志调用；KeysAndValues 原样保存传入的键值对，不含键值对的方法为 nil
+type Call struct {
+	Level         logger.Level
+	Message       string
+	KeysAndValues []interface{}
+}
+
+// Logger 记录每次日志调用，可并发使用。零值即可用，Enabled 对所有等级返回 true。
+type Logger struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ logger.Interface = (*Logger)(nil)
+
+// New 返回一个空的 Logger
+func New() *Logger { return &Logger{} }
+
+// Calls 返回到目前为止记录的调用副本，按调用顺序排列
+func (l *Logger) Calls() []Call {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Call(nil), l.calls...)
+}
+
+// Reset 清空已记录的调用
+func (l *Logger) Reset() {
+	l.mu.Lock()
+	l.calls = nil
+	l.mu.Unlock()
+}
+
+func (l *Logger) record(level logger.Level, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	l.calls = append(l.calls, Call{Level: level, Message: msg, KeysAndValues: keysAndValues})
+	l.mu.Unlock()
+}
+
+func (l *Logger) Trace(msg string) { l.record(logger.TRACE, msg, nil) }
+func (l *Logger) Debug(msg string) { l.record(logger.DEBUG, msg, nil) }
+func (l *Logger) Info(msg string)  { l.record(logger.INFO, msg, nil) }
+func (l *Logger) Warn(msg string)  { l.record(logger.WARN, msg, nil) }
+func (l *Logger) Error(msg string) { l.record(logger.ERROR, msg, nil) }
+
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.record(logger.TRACE, msg, keysAndValues)
+}
+
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.record(logger.DEBUG, msg, keysAndValues)
+}
+
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.record(logger.INFO, msg, keysAndValues)
+}
+
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.record(logger.WARN, msg, keysAndValues)
+}
+
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.record(logger.ERROR, msg, keysAndValues)
+}
+
+func (l *Logger) TraceCtx(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.record(logger.TRACE, msg, keysAndValues)
+}
+
+func (l *Logger) DebugCtx(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.record(logger.DEBUG, msg, keysAndValues)
+}
+
+func (l *Logger) InfoCtx(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.record(logger.INFO, msg, keysAndValues)
+}
+
+func (l *Logger) WarnCtx(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.record(logger.WARN, msg, keysAndValues)
+}
+
+func (l *Logger) ErrorCtx(_ context.Context, msg string, keysAndValues ...interface{}) {
+	l.record(logger.ERROR, msg, keysAndValues)
+}
+
+func (l *Logger) Enabled(logger.Level) bool { return true }
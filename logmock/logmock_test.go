@@ -0,0 +1,44 @@
+package logmock
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/xiangxu05/logger"
+)
+
+// 两种实现都满足 logger.Interface，否则无法编译
+var (
+	_ logger.Interface = (*logger.Logger)(nil)
+	_ logger.Interface = (*Logger)(nil)
+)
+
+// 依赖 logger.Interface 的示例代码
+func handle(log logger.Interface, user string) {
+	log.Infow("login", "user", user)
+	log.ErrorCtx(context.Background(), "quota exceeded")
+}
+
+// 测试 Logger 按顺序记录各方法的等级、消息与键值对，*logger.Logger 与 NewNop 同样可以传入
+func TestLoggerRecordsCalls(t *testing.T) {
+	m := New()
+	handle(m, "alice")
+	m.Warn("plain")
+
+	want := []Call{
+		{Level: logger.INFO, Message: "login", KeysAndValues: []interface{}{"user", "alice"}},
+		{Level: logger.ERROR, Message: "quota exceeded"},
+		{Level: logger.WARN, Message: "plain"},
+	}
+	if got := m.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %+v; want %+v", got, want)
+	}
+	m.Reset()
+	if got := m.Calls(); len(got) != 0 {
+		t.Errorf("Calls() after Reset = %+v", got)
+	}
+
+	var real logger.Interface = logger.NewNop()
+	handle(real, "bob")
+}
@@ -0,0 +1,118 @@
+package logger
+
+import "context"
+
+// MultiLogger 把每次日志调用分发给多个 Logger，各子 Logger 按自己的等级和格式过滤、输出。
+// 例如一个子 Logger 以彩色 plain 格式输出到控制台，另一个以 JSON 格式写文件。
+type MultiLogger struct {
+	loggers []*Logger
+}
+
+// NewMulti 创建分发到 loggers 的 MultiLogger，nil 元素会被忽略
+func NewMulti(loggers ...*Logger) *MultiLogger {
+	m := &MultiLogger{loggers: make([]*Logger, 0, len(loggers))}
+	for _, l := range loggers {
+		if l != nil {
+			m.loggers = append(m.loggers, l)
+		}
+	}
+	return m
+}
+
+// Enabled 只要有一个子 Logger 会输出该等级就返回 true
+func (m *MultiLogger) Enabled(level Level) bool {
+	for _, l := range m.loggers {
+		if l.enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// log 的 depth 含义与 Logger.log 相同，这里多出的一层由子 Logger 的调用补上
+func (m *MultiLogger) log(depth int, level Level, msg string, fields []Field) {
+	for _, l := range m.loggers {
+		l.log(depth+1, level, msg, fields)
+	}
+}
+
+func (m *MultiLogger) logw(level Level, msg string, keysAndValues []interface{}) {
+	if !m.Enabled(level) {
+		return
+	}
+	m.log(2, level, msg, sweetenFields(keysAndValues))
+}
+
+func (m *MultiLogger) Trace(msg string) { m.log(1, TRACE, msg, nil) }
+func (m *MultiLogger) Info(msg string)  { m.log(1, INFO, msg, nil) }
+func (m *MultiLogger) Error(msg string) { m.log(1, ERROR, msg, nil) }
+func (m *MultiLogger) Debug(msg string) { m.log(1, DEBUG, msg, nil) }
+func (m *MultiLogger) Warn(msg string)  { m.log(1, WARN, msg, nil) }
+
+func (m *MultiLogger) Tracew(msg string, keysAndValues ...interface{}) {
+	m.logw(TRACE, msg, keysAndValues)
+}
+func (m *MultiLogger) Infow(msg string, keysAndValues ...interface{}) {
+	m.logw(INFO, msg, keysAndValues)
+}
+func (m *MultiLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	m.logw(ERROR, msg, keysAndValues)
+}
+func (m *MultiLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	m.logw(DEBUG, msg, keysAndValues)
+}
+func (m *MultiLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	m.logw(WARN, msg, keysAndValues)
+}
+
+// With 对每个子 Logger 调用 With，返回新的 MultiLogger
+func (m *MultiLogger) With(keysAndValues ...interface{}) *MultiLogger {
+	child := &MultiLogger{loggers: make([]*Logger, len(m.loggers))}
+	for i, l := range m.loggers {
+		child.loggers[i] = l.With(keysAndValues...)
+	}
+	return child
+}
+
+// Named 对每个子 Logger 调用 Named，返回新的 MultiLogger
+func (m *MultiLogger) Named(name string) *MultiLogger {
+	child := &MultiLogger{loggers: make([]*Logger, len(m.loggers))}
+	for i, l := range m.loggers {
+		child.loggers[i] = l.Named(name)
+	}
+	return child
+}
+
+// Group 对每个子 Logger 调用 Group，返回新的 MultiLogger
+func (m *MultiLogger) Group(name string) *MultiLogger {
+	child := &MultiLogger{loggers: make([]*Logger, len(m.loggers))}
+	for i, l := range m.loggers {
+		child.loggers[i] = l.Group(name)
+	}
+	return child
+}
+
+// Flush 依次等待每个子 Logger 写完已入队的日志
+func (m *MultiLogger) Flush() {
+	for _, l := range m.loggers {
+		l.Flush()
+	}
+}
+
+// Close 关闭所有子 Logger
+func (m *MultiLogger) Close() {
+	for _, l := range m.loggers {
+		l.Close()
+	}
+}
+
+// CloseContext 依次关闭子 Logger，返回遇到的第一个错误；ctx 到期后剩余的子 Logger 也会立即返回
+func (m *MultiLogger) CloseContext(ctx context.Context) error {
+	var first error
+	for _, l := range m.loggers {
+		if err := l.CloseContext(ctx); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
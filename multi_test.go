@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 测试 MultiLogger 把日志分发给各子 Logger，且各自按自己的等级和格式输出
+func TestMultiLogger(t *testing.T) {
+	plain, plainBuf := newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG, Format: FormatPlain})
+	jsonLog, jsonBuf := newBufferLogger(t, Config{Synchronous: true, MinLevel: WARN, Format: FormatJSON})
+	m := NewMulti(plain, nil, jsonLog)
+
+	m.Debug("debug line")
+	m.Infow("info line", "k", 1)
+	line := here() + 1
+	m.With("req", "r1").Error("error line")
+	m.Close()
+
+	plainOut := plainBuf.String()
+	for _, want := range []string{"[DEBUG]", "debug line", "info line k=1", "error line req=r1"} {
+		if !strings.Contains(plainOut, want) {
+			t.Errorf("plain output missing %q:\n%s", want, plainOut)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(jsonBuf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("JSON child got %d lines; want only the ERROR:\n%s", len(lines), jsonBuf.String())
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[0], err)
+	}
+	if data["message"] != "error line" || data["req"] != "r1" {
+		t.Errorf("JSON child = %v", data)
+	}
+	wantCaller := "multi_test.go:" + strconv.Itoa(line)
+	if caller, _ := data["caller"].(string); !strings.HasPrefix(caller, wantCaller) {
+		t.Errorf("caller = %q; want prefix %q", caller, wantCaller)
+	}
+}
+
+// 测试 MultiLogger.Enabled 在任一子 Logger 启用该等级时返回 true
+func TestMultiLoggerEnabled(t *testing.T) {
+	debug, _ := newBufferLogger(t, Config{Synchronous: true, MinLevel: DEBUG})
+	warn, _ := newBufferLogger(t, Config{Synchronous: true, MinLevel: WARN})
+	m := NewMulti(warn, debug)
+	defer m.Close()
+	if !m.Enabled(DEBUG) || m.Enabled(TRACE) {
+		t.Errorf("Enabled(DEBUG), Enabled(TRACE) = %v, %v; want true, false", m.Enabled(DEBUG), m.Enabled(TRACE))
+	}
+	if NewMulti().Enabled(ERROR) {
+		t.Errorf("empty MultiLogger reports ERROR as enabled")
+	}
+}
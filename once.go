@@ -0,0 +1,13 @@
+package logger
+
+// WarnOnce 以 WARN 输出 msg，但同一个 key 在 Logger（含 Named、With 派生的 Logger）生命周期内只输出一次，
+// 适合弃用提示等只需提醒一次的警告。等级被过滤时不记录 key，之后启用了 WARN 仍会输出。
+func (l *Logger) WarnOnce(key, msg string) {
+	if !l.enabled(WARN) {
+		return
+	}
+	if _, seen := l.onceKeys.LoadOrStore(key, struct{}{}); seen {
+		return
+	}
+	l.log(1, WARN, msg, nil)
+}
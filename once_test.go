@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+)
+
+// 测试同一个 key 并发多次调用 WarnOnce 只输出一次，不同 key 各输出一次，派生 Logger 共享已见过的 key
+func TestWarnOnce(t *testing.T) {
+	log, capture := NewCapturing()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.WarnOnce("deprecated-flag", "--old is deprecated")
+		}()
+	}
+	wg.Wait()
+	log.Named("sub").WarnOnce("deprecated-flag", "--old is deprecated")
+	log.WarnOnce("other", "another notice")
+
+	recs := capture.Lines()
+	if len(recs) != 2 || recs[0].Message != "--old is deprecated" || recs[1].Message != "another notice" {
+		t.Fatalf("messages = %q; want each key once", capture.Messages())
+	}
+	if recs[0].Level != WARN {
+		t.Errorf("level = %v; want WARN", recs[0].Level)
+	}
+
+	// 被过滤时不记录 key
+	log.SetLevel(ERROR)
+	log.WarnOnce("later", "filtered")
+	log.SetLevel(WARN)
+	log.WarnOnce("later", "shown once enabled")
+	if msgs := capture.Messages(); len(msgs) != 3 || msgs[2] != "shown once enabled" {
+		t.Errorf("messages = %q; want the filtered key to emit after re-enabling", msgs)
+	}
+}
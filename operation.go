@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// CorrelationIDField 是 NewOperation 添加的字段名
+const CorrelationIDField = "correlation_id"
+
+// NewOperation 返回带有新关联 ID（correlation_id 字段）的派生 Logger，同一操作内的日志通过它共享该 ID。
+// 适合后台任务等没有请求 ID 可用的场景；ID 由 Config.CorrelationIDFunc 生成，默认为随机 UUID。
+func (l *Logger) NewOperation() *Logger {
+	if l.nop {
+		return l
+	}
+	l.mu.RLock()
+	gen := l.config.CorrelationIDFunc
+	l.mu.RUnlock()
+	if gen == nil {
+		gen = newCorrelationID
+	}
+	return l.With(CorrelationIDField, gen())
+}
+
+// newCorrelationID 生成 RFC 4122 第 4 版格式的随机 UUID
+func newCorrelationID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
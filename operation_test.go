@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"regexp"
+	"testing"
+)
+
+// 测试不同操作得到不同的关联 ID，同一操作内的日志共享 ID
+func TestNewOperation(t *testing.T) {
+	log, capture := NewCapturing()
+	first := log.NewOperation()
+	first.Info("step 1")
+	first.Infow("step 2", "n", 2)
+	log.NewOperation().Info("other")
+
+	recs := capture.Lines()
+	if len(recs) != 3 {
+		t.Fatalf("got %d records; want 3", len(recs))
+	}
+	id, _ := fieldValue(recs[0].Fields, CorrelationIDField).(string)
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Fatalf("correlation ID = %q; want a UUID", id)
+	}
+	if got := fieldValue(recs[1].Fields, CorrelationIDField); got != id {
+		t.Errorf("second log of the operation has ID %v; want %s", got, id)
+	}
+	if got := fieldValue(recs[2].Fields, CorrelationIDField); got == id {
+		t.Errorf("second operation reused ID %s", id)
+	}
+}
+
+// 测试 CorrelationIDFunc 替换默认生成器
+func TestCorrelationIDFunc(t *testing.T) {
+	log, capture := NewCapturing()
+	cfg := log.Config()
+	n := 0
+	cfg.CorrelationIDFunc = func() string { n++; return "job-" + string(rune('0'+n)) }
+	if err := log.Reconfigure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	log.NewOperation().Info("a")
+	log.NewOperation().Info("b")
+
+	recs := capture.Lines()
+	if len(recs) != 2 || fieldValue(recs[0].Fields, CorrelationIDField) != "job-1" || fieldValue(recs[1].Fields, CorrelationIDField) != "job-2" {
+		t.Errorf("records = %+v; want job-1 and job-2", recs)
+	}
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOSLogUnsupported 在非 macOS 平台（或禁用 cgo 的构建）启用 OutputOSLog 时返回
+var ErrOSLogUnsupported = errors.New("logger: os_log is only supported on darwin with cgo")
+
+// osLogType 与 os_log_type_t 的取值一致
+type osLogType uint8
+
+const (
+	osLogDefault osLogType = 0x00
+	osLogInfo    osLogType = 0x01
+	osLogDebug   osLogType = 0x02
+	osLogError   osLogType = 0x10
+	osLogFault   osLogType = 0x11
+)
+
+// osLogSink 是统一日志系统句柄的最小接口，便于在测试中替换
+type osLogSink interface {
+	Log(typ osLogType, msg string)
+	Close() error
+}
+
+// osLogSubsystem 返回 os_log 的 subsystem，未配置时使用可执行文件名（不含扩展名）
+func osLogSubsystem(cfg Config) string {
+	if cfg.OSLogSubsystem != "" {
+		return cfg.OSLogSubsystem
+	}
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// osLogTypeFor 按等级映射 os_log 类型：TRACE/DEBUG 为 debug，INFO 为 info，WARN 为 default，
+// ERROR 为 error，FATAL 为 fault
+func osLogTypeFor(level Level) osLogType {
+	switch {
+	case level >= FATAL:
+		return osLogFault
+	case level == ERROR:
+		return osLogError
+	case level == WARN:
+		return osLogDefault
+	case level == INFO:
+		return osLogInfo
+	default:
+		return osLogDebug
+	}
+}
+
+func writeOSLog(sink osLogSink, level Level, msg string) {
+	sink.Log(osLogTypeFor(level), strings.TrimRight(msg, "\n"))
+}
@@ -0,0 +1,44 @@
+//go:build darwin && cgo
+
+package logger
+
+/*
+#include <os/log.h>
+#include <stdlib.h>
+
+// os_log_with_type 是要求格式串为字面量的宏，因此包一层；%{public}s 使内容在 Console.app 中可见
+static void logger_os_log(os_log_t log, uint8_t type, const char *msg) {
+	os_log_with_type(log, (os_log_type_t)type, "%{public}s", msg);
+}
+
+static void logger_os_release(os_log_t log) {
+	os_release(log);
+}
+*/
+import "C"
+
+import "unsafe"
+
+type osLog struct {
+	handle C.os_log_t
+}
+
+// openOSLog 以 subsystem 创建 os_log 句柄，类别固定为 "default"
+func openOSLog(subsystem string) (osLogSink, error) {
+	cs := C.CString(subsystem)
+	defer C.free(unsafe.Pointer(cs))
+	category := C.CString("default")
+	defer C.free(unsafe.Pointer(category))
+	return &osLog{handle: C.os_log_create(cs, category)}, nil
+}
+
+func (o *osLog) Log(typ osLogType, msg string) {
+	cs := C.CString(msg)
+	defer C.free(unsafe.Pointer(cs))
+	C.logger_os_log(o.handle, C.uint8_t(typ), cs)
+}
+
+func (o *osLog) Close() error {
+	C.logger_os_release(o.handle)
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !darwin || !cgo
+
+package logger
+
+func openOSLog(subsystem string) (osLogSink, error) {
+	return nil, ErrOSLogUnsupported
+}
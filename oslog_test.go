@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeOSLog 记录写入的 os_log 类型与内容
+type fakeOSLog struct {
+	mu      sync.Mutex
+	types   []osLogType
+	entries []string
+	closed  bool
+}
+
+func (f *fakeOSLog) Log(typ osLogType, msg string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.types = append(f.types, typ)
+	f.entries = append(f.entries, msg)
+}
+
+func (f *fakeOSLog) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+// 测试等级到 os_log 类型的映射，以及 Close 释放句柄
+func TestOSLogMapping(t *testing.T) {
+	fake := &fakeOSLog{}
+	log := New(Config{MinLevel: TRACE, Targets: OutputNone, IncludeCaller: new(bool)})
+	log.mu.Lock()
+	log.config.Targets = OutputOSLog
+	log.osLog = fake
+	log.mu.Unlock()
+
+	log.Trace("t")
+	log.Debug("d")
+	log.Info("i")
+	log.Warn("w")
+	log.Error("e")
+	log.Close()
+
+	want := []osLogType{osLogDebug, osLogDebug, osLogInfo, osLogDefault, osLogError}
+	if len(fake.types) != len(want) {
+		t.Fatalf("got %d entries; want %d", len(fake.types), len(want))
+	}
+	for i, typ := range fake.types {
+		if typ != want[i] {
+			t.Errorf("entry %d type = %#x; want %#x", i, typ, want[i])
+		}
+	}
+	if last := fake.entries[len(fake.entries)-1]; !strings.HasPrefix(last, "[ERROR]") || strings.HasSuffix(last, "\n") {
+		t.Errorf("last entry = %q", last)
+	}
+	if got := osLogTypeFor(FATAL); got != osLogFault {
+		t.Errorf("FATAL maps to %#x; want fault", got)
+	}
+	if !fake.closed {
+		t.Errorf("Close did not release the os_log handle")
+	}
+}
+
+// 测试非 macOS 平台启用 OutputOSLog 时返回明确的错误
+func TestOSLogUnsupported(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("os_log is supported on darwin")
+	}
+	log, err := NewWithError(Config{Targets: OutputOSLog})
+	if log != nil || !errors.Is(err, ErrOSLogUnsupported) {
+		t.Errorf("NewWithError = %v, %v; want nil, ErrOSLogUnsupported", log, err)
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+const defaultBufferSize = 1000
+
+// enqueueOverflow 在通道已满或队列非空时把日志追加到溢出队列（Logger 的 overflow 等字段，由 overflowMu 保护），
+// 它是通道之后的第二级缓冲：overflowing 为 true 期间新日志都追加到队列而不再进入通道，直到 start() 把它取空，
+// 因此同一协程先后写入的日志总是按顺序输出。队列也满时写入 FallbackWriter，未配置时按溢出策略丢弃或等待 start() 取空队列。
+func (l *Logger) enqueueOverflow(ctx context.Context, msg logMsg) {
+	// 第一次需要等待时才创建，EnqueueTimeout 从那时起算，覆盖之后的全部等待
+	var expired <-chan time.Time
+	for {
+		l.overflowMu.Lock()
+		if len(l.overflow) == 0 {
+			select {
+			case l.logChan <- msg:
+				l.overflowMu.Unlock()
+				return
+			default:
+			}
+		}
+		limit := int(l.maxOverflow.Load())
+		if len(l.overflow) < limit {
+			l.overflow = append(l.overflow, msg)
+			l.overflowing.Store(true)
+			l.overflowMu.Unlock()
+			select {
+			case l.overflowReady <- struct{}{}:
+			default:
+			}
+			return
+		}
+		empty, space := len(l.overflow) == 0, l.overflowSpace
+		l.overflowMu.Unlock()
+
+		if l.writeFallback(msg) {
+			return
+		}
+		if l.dropOnFull.Load() {
+			l.countDrop()
+			return
+		}
+		if expired == nil {
+			expired = l.enqueueExpiry()
+		}
+		if empty {
+			// 队列已停用（limit 为 0）且为空，直接阻塞在通道上
+			select {
+			case l.logChan <- msg:
+			case <-ctx.Done():
+				l.countDrop()
+			case <-expired:
+				l.countDrop()
+			case <-l.done:
+				l.countDrop()
+			}
+			return
+		}
+		select {
+		case <-space:
+		case <-ctx.Done():
+			l.countDrop()
+			return
+		case <-expired:
+			l.countDrop()
+			return
+		case <-l.done:
+			l.countDrop()
+			return
+		}
+	}
+}
+
+// drainOverflow 写出通道中现有的日志与溢出队列中的全部日志，只在 start() 中调用。
+// 持 overflowMu 先取出通道中现有的日志、再取出队列：队列中任一条日志入队时，同一协程更早的日志要么已写出，要么还在通道中。
+func (l *Logger) drainOverflow() {
+	l.overflowMu.Lock()
+	var batch []logMsg
+	for more := true; more; {
+		select {
+		case msg := <-l.logChan:
+			batch = append(batch, msg)
+		default:
+			more = false
+		}
+	}
+	batch = append(batch, l.overflow...)
+	l.overflow = nil
+	l.overflowing.Store(false)
+	close(l.overflowSpace)
+	l.overflowSpace = make(chan struct{})
+	l.overflowMu.Unlock()
+
+	for _, msg := range batch {
+		l.write(msg)
+	}
+}
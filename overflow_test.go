@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 测试多个协程并发写入时溢出队列不丢日志，且每个协程内部的顺序保持不变
+func TestOverflowBufferOrder(t *testing.T) {
+	const producers, perProducer = 16, 500
+	log, buf := newBufferLogger(t, Config{MinLevel: INFO, BufferSize: 4, OverflowBufferSize: 64})
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				log.Info(fmt.Sprintf("p%d %d", p, i))
+			}
+		}(p)
+	}
+	wg.Wait()
+	log.Close()
+
+	next := make(map[string]int)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != producers*perProducer {
+		t.Fatalf("got %d lines; want %d", len(lines), producers*perProducer)
+	}
+	for _, line := range lines {
+		f := strings.Fields(line)
+		producer, seq := f[len(f)-2], f[len(f)-1]
+		n, err := strconv.Atoi(seq)
+		if err != nil {
+			t.Fatalf("unexpected line %q", line)
+		}
+		if n != next[producer] {
+			t.Fatalf("%s: got seq %d; want %d", producer, n, next[producer])
+		}
+		next[producer]++
+	}
+}
+
+// 测试通道已满时突发日志进入溢出队列而不阻塞调用方，写出顺序与写入顺序一致
+func TestOverflowBufferAbsorbsBurst(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO, BufferSize: 10, OverflowBufferSize: 100})
+	gate := newGateWriter()
+	log.fileLogger = gate
+
+	returned := make(chan struct{})
+	go func() {
+		for i := 0; i < 110; i++ {
+			log.Info("burst " + strconv.Itoa(i))
+		}
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("burst within BufferSize+OverflowBufferSize blocked the caller")
+	}
+	close(gate.release)
+	log.Close()
+
+	lines := strings.Split(strings.TrimSpace(gate.String()), "\n")
+	if len(lines) != 110 {
+		t.Fatalf("got %d lines; want 110", len(lines))
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, " burst "+strconv.Itoa(i)) {
+			t.Fatalf("line %d = %q; want burst %d", i, line, i)
+		}
+	}
+}
+
+// 测试通道已满时日志直接写入 FallbackWriter 并单独计数，不丢弃也不阻塞
+func TestFallbackWriter(t *testing.T) {
+	fallback := &syncBuffer{}
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO, BufferSize: 4, FallbackWriter: fallback})
+	gate := newGateWriter()
+	log.fileLogger = gate
+
+	const total = 20
+	for i := 0; i < total; i++ {
+		log.Infow("burst", "i", i)
+	}
+	stats := log.Stats()
+	inFallback := strings.Count(fallback.String(), "burst")
+	if inFallback == 0 || uint64(inFallback) != stats.Fallback {
+		t.Fatalf("fallback has %d lines, Stats().Fallback = %d; want the same non-zero count", inFallback, stats.Fallback)
+	}
+	if strings.Contains(fallback.String(), "\x1b") {
+		t.Errorf("fallback output should not be colorized: %q", fallback.String())
+	}
+
+	close(gate.release)
+	log.Close()
+	if stats := log.Stats(); stats.Dropped != 0 {
+		t.Errorf("Dropped = %d; want 0", stats.Dropped)
+	}
+	if written := strings.Count(gate.String(), "burst"); written+inFallback != total {
+		t.Errorf("written %d + fallback %d != %d", written, inFallback, total)
+	}
+}
+
+// 测试 EnqueueTimeout：消费协程停顿时，通道或溢出队列已满的调用在超时后返回并计为丢弃
+func TestEnqueueTimeout(t *testing.T) {
+	for _, overflow := range []int{0, 4} {
+		const timeout = 50 * time.Millisecond
+		log, _ := newBufferLogger(t, Config{MinLevel: INFO, EnqueueTimeout: timeout, OverflowBufferSize: overflow})
+		gate := newGateWriter()
+		log.fileLogger = gate
+
+		// 第一条卡在写入器中，等消费协程取走后再填满通道与溢出队列
+		log.Info("stalled")
+		for len(log.logChan) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		for i := 0; i < cap(log.logChan)+overflow; i++ {
+			log.Info("fill " + strconv.Itoa(i))
+		}
+		start := time.Now()
+		log.Info("timed out")
+		if elapsed := time.Since(start); elapsed < timeout || elapsed > timeout+time.Second {
+			t.Errorf("overflow %d: blocked call returned after %v; want about %v", overflow, elapsed, timeout)
+		}
+		if got := log.Stats().Dropped; got != 1 {
+			t.Errorf("overflow %d: Dropped = %d; want 1", overflow, got)
+		}
+		close(gate.release)
+		log.Close()
+		if strings.Contains(gate.String(), "timed out") {
+			t.Errorf("overflow %d: timed out message was written", overflow)
+		}
+	}
+}
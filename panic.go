@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// stackFrame 是结构化 panic 日志中 stack 数组的一项
+type stackFrame struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Source   string `json:"source,omitempty"` // PanicOptions.SourcePrefixes 匹配时该行的源代码
+}
+
+// PanicOptions 调整 RecoverAndLogPanicWith 记录的 panic 内容
+type PanicOptions struct {
+	// SourcePrefixes 非空时，为文件路径以其中任一前缀开头的栈帧附加该行源代码（去掉首尾空白），
+	// 通常设为本项目的源码目录以排除依赖与标准库。读取失败的文件直接跳过。
+	SourcePrefixes []string
+}
+
+// sourceReader 在一次 panic 记录中缓存已读取的源文件，读取失败的文件记为 nil
+type sourceReader struct {
+	prefixes []string
+	files    map[string][]string
+}
+
+func (r *sourceReader) line(file string, line int) (string, bool) {
+	if !r.matches(file) {
+		return "", false
+	}
+	lines, ok := r.files[file]
+	if !ok {
+		if data, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		if r.files == nil {
+			r.files = make(map[string][]string)
+		}
+		r.files[file] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return "", false
+	}
+	return strings.TrimSpace(lines[line-1]), true
+}
+
+func (r *sourceReader) matches(file string) bool {
+	for _, p := range r.prefixes {
+		if p != "" && strings.HasPrefix(file, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// annotateFrames 为匹配 SourcePrefixes 的帧填入 Source
+func (o PanicOptions) annotateFrames(frames []stackFrame) []stackFrame {
+	if len(o.SourcePrefixes) == 0 {
+		return frames
+	}
+	r := &sourceReader{prefixes: o.SourcePrefixes}
+	for i, f := range frames {
+		if src, ok := r.line(f.File, f.Line); ok {
+			frames[i].Source = src
+		}
+	}
+	return frames
+}
+
+// annotateStack 在文本栈中每个匹配 SourcePrefixes 的 "\tfile:line +0x.." 行之后插入一行 "\t\t> 源代码"
+func (o PanicOptions) annotateStack(stack []byte) []byte {
+	if len(o.SourcePrefixes) == 0 {
+		return stack
+	}
+	r := &sourceReader{prefixes: o.SourcePrefixes}
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(stack, []byte("\n")) {
+		out.Write(line)
+		file, n, ok := parseStackLocation(string(line))
+		if !ok {
+			continue
+		}
+		if src, ok := r.line(file, n); ok {
+			if !bytes.HasSuffix(line, []byte("\n")) {
+				out.WriteByte('\n')
+			}
+			out.WriteString("\t\t> " + src + "\n")
+		}
+	}
+	return out.Bytes()
+}
+
+// parseStackLocation 解析 runtime.Stack 输出中形如 "\t/path/file.go:12 +0x1d" 的位置行
+func parseStackLocation(line string) (string, int, bool) {
+	if !strings.HasPrefix(line, "\t") {
+		return "", 0, false
+	}
+	loc := strings.TrimSpace(line)
+	if i := strings.LastIndex(loc, " +0x"); i >= 0 {
+		loc = loc[:i]
+	}
+	i := strings.LastIndexByte(loc, ':')
+	if i <= 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(loc[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return loc[:i], n, true
+}
+
+const (
+	defaultPanicStackSize = 64 << 10
+	maxPanicStackSize     = 16 << 20
+)
+
+// panicStack 返回当前协程的完整文本调用栈：缓冲区写满时加倍重试，超过 maxPanicStackSize 后截断
+func panicStack(size int) []byte {
+	if size <= 0 {
+		size = defaultPanicStackSize
+	}
+	for {
+		buf := make([]byte, size)
+		n := runtime.Stack(buf, false)
+		if n < len(buf) || size >= maxPanicStackSize {
+			return buf[:n]
+		}
+		size *= 2
+	}
+}
+
+// maxPanicFrames 限制结构化栈的帧数，与纯文本栈的缓冲区上限作用相同
+const maxPanicFrames = 64
+
+// callerFrames 返回从第 skip 层开始的调用栈，skip 为 0 表示 callerFrames 的直接调用者
+func callerFrames(skip int) []stackFrame {
+	pcs := make([]uintptr, maxPanicFrames)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var out []stackFrame
+	for {
+		f, more := frames.Next()
+		out = append(out, stackFrame{File: f.File, Line: f.Line, Function: f.Function})
+		if !more {
+			return out
+		}
+	}
+}
+
+// Go 在新协程中运行 fn，fn 发生 panic 时像 RecoverAndLogPanic 一样记录 panic 与调用栈，
+// 而不是让整个进程崩溃。panic 之后 fn 不会被重新执行。
+func (l *Logger) Go(fn func()) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// 栈帧：本匿名函数 -> runtime.gopanic -> 发生 panic 的函数
+				l.logPanic(r, 2)
+			}
+		}()
+		fn()
+	}()
+}
@@ -0,0 +1,46 @@
+// Package promlog 将 logger 的内部计数器导出为 Prometheus 指标。
+// 作为独立模块发布（自带 go.mod），只有依赖该模块的程序才会引入 Prometheus 客户端，核心模块保持无此依赖。
+package promlog
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xiangxu05/logger"
+)
+
+var (
+	messagesDesc = prometheus.NewDesc(
+		"logger_messages_total",
+		"Number of log messages written, by level.",
+		[]string{"level"}, nil,
+	)
+	droppedDesc = prometheus.NewDesc(
+		"logger_dropped_total",
+		"Number of log messages dropped.",
+		nil, nil,
+	)
+)
+
+type collector struct {
+	log *logger.Logger
+}
+
+// Collectors 返回基于 l.Stats() 的 Prometheus 采集器，注册后即可抓取
+// logger_messages_total{level=...} 与 logger_dropped_total。
+func Collectors(l *logger.Logger) []prometheus.Collector {
+	return []prometheus.Collector{&collector{log: l}}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- messagesDesc
+	ch <- droppedDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.log.Stats()
+	for level, n := range stats.Messages {
+		ch <- prometheus.MustNewConstMetric(messagesDesc, prometheus.CounterValue, float64(n), strings.ToLower(level.String()))
+	}
+	ch <- prometheus.MustNewConstMetric(droppedDesc, prometheus.CounterValue, float64(stats.Dropped))
+}
@@ -0,0 +1,53 @@
+package promlog
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xiangxu05/logger"
+)
+
+// 测试按等级记录的日志数能通过 registry 读到
+func TestCollectors(t *testing.T) {
+	log, capture := logger.NewCapturing()
+	defer log.Close()
+
+	log.Info("a")
+	log.Info("b")
+	log.Warn("c")
+	log.Error("d")
+	capture.Lines() // 等待全部写出
+
+	reg := prometheus.NewPedanticRegistry()
+	for _, c := range Collectors(log) {
+		reg.MustRegister(c)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+
+	got := map[string]float64{}
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			name := mf.GetName()
+			for _, lp := range m.GetLabel() {
+				name += "{" + lp.GetValue() + "}"
+			}
+			got[name] = m.GetCounter().GetValue()
+		}
+	}
+
+	want := map[string]float64{
+		"logger_messages_total{info}":  2,
+		"logger_messages_total{warn}":  1,
+		"logger_messages_total{error}": 1,
+		"logger_messages_total{debug}": 0,
+		"logger_dropped_total":         0,
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v; want %v", k, got[k], v)
+		}
+	}
+}
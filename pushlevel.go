@@ -0,0 +1,53 @@
+package logger
+
+import "slices"
+
+// levelPush 是 PushLevel 压入的一层，prev 为这一层生效前的等级
+type levelPush struct {
+	prev Level
+}
+
+// PushLevel 把最低等级改为 level，返回的 restore 恢复为调用前的等级，便于 defer log.PushLevel(DEBUG)() 这样
+// 只在一个作用域内调整等级。嵌套调用按后进先出恢复；外层先于内层恢复时，内层恢复后回到最外层之前的等级。
+// restore 可重复调用，只有第一次生效。PushLevel 会取消尚未到期的 BoostLevel，作用域内的 BoostLevel 到期后
+// 回到压入的等级；SetLevel 与 Reconfigure 会丢弃所有未恢复的层，之后调用 restore 不再修改等级。
+// 对克隆只作用于克隆自己的等级。
+func (l *Logger) PushLevel(level Level) (restore func()) {
+	if l.nop {
+		return func() {}
+	}
+	s := l.levels()
+	s.boostMu.Lock()
+	defer s.boostMu.Unlock()
+	prev := Level(s.minLevel.Load())
+	if s.boostTimer != nil {
+		// 提升被取消，作用域结束后应回到它本来要恢复的等级
+		s.boostTimer.Stop()
+		s.boostTimer = nil
+		prev = s.boostBase
+	}
+	p := &levelPush{prev: prev}
+	s.levelStack = append(s.levelStack, p)
+	s.set(level)
+	return func() { s.pop(p) }
+}
+
+func (s *levelState) pop(p *levelPush) {
+	s.boostMu.Lock()
+	defer s.boostMu.Unlock()
+	i := slices.Index(s.levelStack, p)
+	if i < 0 {
+		return
+	}
+	if i < len(s.levelStack)-1 {
+		// 不是最内层：上一层恢复时应回到这一层之前的等级
+		s.levelStack[i+1].prev = p.prev
+	} else {
+		if s.boostTimer != nil {
+			s.boostTimer.Stop()
+			s.boostTimer = nil
+		}
+		s.set(p.prev)
+	}
+	s.levelStack = slices.Delete(s.levelStack, i, i+1)
+}
@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"strings"
+	"time"
+)
+
+// WriteRaw 把已格式化好的一行按 level 过滤后原样写入各输出目标（缺少换行时补上），
+// 不经过格式化、着色、脱敏与截断，便于把其他系统的日志接入同一套文件轮转。
+// 原始行没有调用位置，因此不受 PrefixLevels 影响，也不会写入白名单文件。
+func (l *Logger) WriteRaw(level Level, line string) {
+	if l.nop || level < l.levelFloor() {
+		return
+	}
+	l.enqueue(logMsg{
+		Level:     level,
+		Message:   strings.TrimSuffix(line, "\n"), // 换行在输出时统一补上
+		Time:      time.Now(),
+		Component: l.component,
+		Raw:       true,
+	})
+}
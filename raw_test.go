@@ -0,0 +1,23 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+// 测试 WriteRaw 原样写出预格式化的行，只补换行，并按等级过滤
+func TestWriteRaw(t *testing.T) {
+	const line = `2024-01-15T10:00:00Z host=a level=info msg="from upstream" password=secret`
+	log, buf := newBufferLogger(t, Config{Synchronous: true, MinLevel: INFO, Format: FormatJSON, MaskKeys: []string{"password"}})
+	log.WriteRaw(INFO, line)
+	log.WriteRaw(WARN, "already terminated\n")
+	log.WriteRaw(DEBUG, "filtered out")
+	log.Close()
+
+	if got, want := buf.String(), line+"\nalready terminated\n"; got != want {
+		t.Errorf("output = %q; want %q", got, want)
+	}
+	if strings.Contains(buf.String(), "filtered out") {
+		t.Error("WriteRaw below MinLevel was written")
+	}
+}
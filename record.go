@@ -0,0 +1,45 @@
+package logger
+
+import "time"
+
+// LogRecord 是单条日志的公开数据形式，Formatter、Hooks、Subscribe 与 Capture 都使用它
+type LogRecord struct {
+	Level     Level
+	Message   string
+	Time      time.Time
+	Caller    string // "file.go:42 pkg.Func"，未记录调用位置时为空
+	File      string // 调用位置的文件名（不含目录）
+	Line      int
+	Func      string // 调用位置的函数名（含包名，不含导入路径）
+	Component string // Named 设置的子系统名称
+	Seq       uint64 // IncludeSequence 开启时的序号，从 1 开始；未开启时为 0
+	Fields    []Field
+}
+
+func (m logMsg) record() LogRecord {
+	return LogRecord{
+		Level:     m.Level,
+		Message:   m.Message,
+		Time:      m.Time,
+		Caller:    m.Caller,
+		File:      m.File,
+		Line:      m.Line,
+		Func:      m.Func,
+		Component: m.Component,
+		Seq:       m.Seq,
+		Fields:    m.Fields,
+	}
+}
+
+// Hook 在每条日志写出后收到它的 LogRecord（敏感字段已按 MaskKeys 脱敏），在后台写出协程中顺序调用，
+// 不应阻塞；需要耗时处理时自行转交给其他协程。绕过通道直接写出的 panic 日志不经过钩子。
+type Hook func(LogRecord)
+
+// runHooks 依次调用 Config.Hooks，只在 write() 中调用
+func (l *Logger) runHooks(msg logMsg) {
+	rec := msg.record()
+	rec.Fields = l.maskFields(rec.Fields)
+	for _, h := range l.config.Hooks {
+		h(rec)
+	}
+}
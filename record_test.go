@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// 测试 Hooks 收到字段齐全、已脱敏的 LogRecord
+func TestHookReceivesRecord(t *testing.T) {
+	var mu sync.Mutex
+	var got []LogRecord
+	hook := func(r LogRecord) {
+		mu.Lock()
+		got = append(got, r)
+		mu.Unlock()
+	}
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO, MaskKeys: []string{"token"}, Hooks: []Hook{hook}})
+	before := time.Now()
+	log.Named("billing").With("order", 42).Warnw("charge failed", "token", "secret")
+	log.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("hook called %d times; want 1", len(got))
+	}
+	r := got[0]
+	if r.Level != WARN || r.Message != "charge failed" || r.Component != "billing" {
+		t.Errorf("record = %+v; want WARN charge failed from billing", r)
+	}
+	if r.Time.Before(before) || r.Time.After(time.Now()) {
+		t.Errorf("Time = %v; want the time of the call", r.Time)
+	}
+	if r.Caller == "" || r.File != "record_test.go" || r.Line == 0 || r.Func != "logger.TestHookReceivesRecord" {
+		t.Errorf("caller = %q file=%q line=%d func=%q; want this test", r.Caller, r.File, r.Line, r.Func)
+	}
+	if fieldValue(r.Fields, "order") != 42 || fieldValue(r.Fields, "token") != "***" {
+		t.Errorf("fields = %v; want order=42 and a masked token", r.Fields)
+	}
+}
+
+// 测试 OnErrorAlert 对同一签名在冷却期内只触发一次，不同签名各自计算，冷却期过后再次触发
+func TestOnErrorAlertCooldown(t *testing.T) {
+	const cooldown = 100 * time.Millisecond
+	var alerts []LogRecord
+	log, _ := newBufferLogger(t, Config{
+		Synchronous:   true,
+		MinLevel:      INFO,
+		AlertCooldown: cooldown,
+		OnErrorAlert:  func(r LogRecord) { alerts = append(alerts, r) },
+		MaskKeys:      []string{"password"},
+	})
+	defer log.Close()
+	// 同一调用位置，签名相同
+	dbDown := func() { log.Errorw("db unreachable", "password", "secret") }
+
+	for i := 0; i < 100; i++ {
+		dbDown()
+	}
+	log.Warn("db unreachable")
+	log.Error("queue full")
+	if len(alerts) != 2 || alerts[0].Message != "db unreachable" || alerts[1].Message != "queue full" {
+		t.Fatalf("alerts within cooldown = %+v; want one per signature", alerts)
+	}
+	if v := fieldValue(alerts[0].Fields, "password"); v == "secret" {
+		t.Errorf("alert record not masked: %v", alerts[0].Fields)
+	}
+
+	time.Sleep(cooldown + 20*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		dbDown()
+	}
+	if len(alerts) != 3 {
+		t.Errorf("alerts after cooldown = %d; want 3", len(alerts))
+	}
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// dailyWriter 按日期切换日志文件，LogPath 为 logs/app.log 时当天写入 logs/app-2024-01-15.log。
+// 每个日期文件内部仍由 lumberjack 按大小轮转。
+type dailyWriter struct {
+	mu       sync.Mutex
+	onRotate func(oldPath string) // 切换到新日期时以前一天的文件路径调用
+	base     string
+	now      func() time.Time
+	newFile  func(path string) io.WriteCloser
+	date     string
+	current  io.WriteCloser
+}
+
+func newDailyWriter(base string, rotation RotateConfig, onRotate func(string)) *dailyWriter {
+	return &dailyWriter{
+		base:     base,
+		now:      time.Now,
+		onRotate: onRotate,
+		newFile: func(path string) io.WriteCloser {
+			return newRotatingWriter(path, rotation, onRotate)
+		},
+	}
+}
+
+// datedPath 在扩展名前插入日期
+func (w *dailyWriter) datedPath(date string) string {
+	ext := filepath.Ext(w.base)
+	return strings.TrimSuffix(w.base, ext) + "-" + date + ext
+}
+
+func (w *dailyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	date := w.now().Format("2006-01-02")
+	if w.current == nil || date != w.date {
+		if w.current != nil {
+			_ = w.current.Close()
+		}
+		if w.onRotate != nil && w.date != "" && date != w.date {
+			go w.onRotate(w.datedPath(w.date))
+		}
+		w.current = w.newFile(w.datedPath(date))
+		w.date = date
+	}
+	return w.current.Write(p)
+}
+
+func (w *dailyWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.current == nil {
+		return nil
+	}
+	err := w.current.Close()
+	w.current = nil
+	return err
+}
+
+func (w *dailyWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.current == nil {
+		return nil
+	}
+	return syncWriter(w.current)
+}
+
+// newRotatingWriter 创建按大小轮转的文件写入器，onRotate 非空时包装为 rotateNotifier
+func newRotatingWriter(path string, rotation RotateConfig, onRotate func(string)) io.WriteCloser {
+	w := newFileWriter(path, rotation)
+	if onRotate == nil {
+		return w
+	}
+	return &rotateNotifier{Logger: w.(*lumberjack.Logger), onRotate: onRotate}
+}
+
+// rotateNotifier 包装 lumberjack.Logger。lumberjack 不暴露轮转事件，
+// 这里按与它相同的规则根据写入量推算轮转时机，轮转后在独立协程中回调旧文件路径。
+type rotateNotifier struct {
+	*lumberjack.Logger
+	onRotate func(oldPath string)
+
+	mu     sync.Mutex
+	opened bool  // lumberjack 是否已打开文件，关闭后下次写入会重新打开
+	size   int64 // 当前文件的估计大小
+}
+
+func (w *rotateNotifier) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	max := int64(w.MaxSize) * 1024 * 1024
+	if max == 0 {
+		max = 100 * 1024 * 1024 // lumberjack 的默认上限
+	}
+	var rotate bool
+	if !w.opened {
+		// 与 lumberjack 的 openExistingOrNew 一致：已有文件加上本次写入达到上限即轮转
+		w.size = 0
+		if info, err := os.Stat(w.Filename); err == nil {
+			w.size = info.Size()
+			rotate = w.size+int64(len(p)) >= max
+		}
+	} else {
+		rotate = w.size+int64(len(p)) > max
+	}
+	n, err := w.Logger.Write(p)
+	if err != nil {
+		return n, err
+	}
+	w.opened = true
+	if rotate {
+		w.size = int64(n)
+		if old := w.latestBackup(); old != "" {
+			go w.onRotate(old)
+		}
+	} else {
+		w.size += int64(n)
+	}
+	return n, nil
+}
+
+func (w *rotateNotifier) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.opened = false
+	return w.Logger.Close()
+}
+
+// latestBackup 返回 lumberjack 最近一次轮转出的备份文件（name-时间戳.ext），
+// 备份名中的时间戳按字典序即时间顺序。开启压缩时该备份可能已被压缩，此时返回对应的 .gz 路径，
+// 但压缩在后台进行，回调拿到 .ext 路径时文件仍可能随后被替换为 .gz。
+func (w *rotateNotifier) latestBackup() string {
+	dir := filepath.Dir(w.Filename)
+	base := filepath.Base(w.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var latest, latestStamp string
+	for _, e := range entries {
+		name := e.Name()
+		stamp, ok := backupStamp(name, prefix, ext)
+		if !ok {
+			stamp, ok = backupStamp(name, prefix, ext+".gz")
+		}
+		// 同一时间戳的 .ext 与 .gz 并存（正在压缩）时取未压缩的那个
+		if ok && (stamp > latestStamp || stamp == latestStamp && len(name) < len(latest)) {
+			latest, latestStamp = name, stamp
+		}
+	}
+	if latest == "" {
+		return ""
+	}
+	return filepath.Join(dir, latest)
+}
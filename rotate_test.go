@@ -0,0 +1,256 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// fakeClock 是可手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// 测试 RotateDaily 在跨过午夜后切换到新的日期文件
+func TestRotateDailyCrossesMidnight(t *testing.T) {
+	dir := t.TempDir()
+	log := New(Config{
+		MinLevel:    DEBUG,
+		Targets:     OutputFile,
+		LogPath:     filepath.Join(dir, "app.log"),
+		RotateDaily: true,
+	})
+	dw, ok := log.fileLogger.(*dailyWriter)
+	if !ok {
+		t.Fatalf("fileLogger is %T; want *dailyWriter", log.fileLogger)
+	}
+	clock := &fakeClock{now: time.Date(2024, 1, 15, 23, 59, 59, 0, time.Local)}
+	dw.now = clock.Now
+
+	log.Info("before midnight")
+	log.Flush()
+	clock.Set(time.Date(2024, 1, 16, 0, 0, 1, 0, time.Local))
+	log.Info("after midnight")
+	log.Close()
+
+	for file, want := range map[string]string{
+		"app-2024-01-15.log": "before midnight",
+		"app-2024-01-16.log": "after midnight",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		if !strings.Contains(string(data), want) || strings.Count(string(data), "\n") != 1 {
+			t.Errorf("%s = %q; want exactly the %q line", file, data, want)
+		}
+	}
+}
+
+// 测试主日志与白名单日志分别使用各自的轮转设置，未设置时保持默认值
+func TestPerStreamRotation(t *testing.T) {
+	t.Chdir(t.TempDir())
+	noCompress := false
+	cfg := Config{
+		Targets:         OutputFile,
+		LogPath:         filepath.Join(t.TempDir(), "app.log"),
+		AllowedPrefix:   []string{"audit"},
+		FileRotation:    RotateConfig{MaxSize: 50, MaxBackups: 2},
+		AllowedRotation: RotateConfig{MaxAge: 30, Compress: &noCompress},
+	}
+	log := New(cfg)
+	defer log.Close()
+
+	main, ok := log.fileLogger.(*lumberjack.Logger)
+	if !ok {
+		t.Fatalf("fileLogger is %T", log.fileLogger)
+	}
+	if main.MaxSize != 50 || main.MaxBackups != 2 || main.MaxAge != 7 || !main.Compress {
+		t.Errorf("main writer = %d/%d/%d/%v", main.MaxSize, main.MaxBackups, main.MaxAge, main.Compress)
+	}
+
+	allowed, ok := log.allowFileLogger.(*lumberjack.Logger)
+	if !ok {
+		t.Fatalf("allowFileLogger is %T", log.allowFileLogger)
+	}
+	if allowed.MaxSize != 10 || allowed.MaxBackups != 5 || allowed.MaxAge != 30 || allowed.Compress {
+		t.Errorf("allowed writer = %d/%d/%d/%v", allowed.MaxSize, allowed.MaxBackups, allowed.MaxAge, allowed.Compress)
+	}
+}
+
+// 测试按大小轮转和按日期切换时 OnRotate 以旧文件路径被调用
+func TestOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	// 同前缀同扩展名但不是备份的文件，不能被当成轮转出的旧文件
+	decoy := filepath.Join(dir, "app-debug.log")
+	if err := os.WriteFile(decoy, []byte("decoy\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rotated := make(chan string, 4)
+	noCompress := false
+	log := New(Config{
+		MinLevel:     DEBUG,
+		Targets:      OutputFile,
+		LogPath:      filepath.Join(dir, "app.log"),
+		FileRotation: RotateConfig{MaxSize: 1, Compress: &noCompress},
+		OnRotate:     func(old string) { rotated <- old },
+	})
+	line := strings.Repeat("x", 4096)
+	for i := 0; i < 300; i++ {
+		log.Info(line)
+	}
+	log.Close()
+
+	select {
+	case old := <-rotated:
+		if filepath.Dir(old) != dir || !isBackupName(filepath.Base(old), "app-", ".log") {
+			t.Errorf("rotated path = %q; want app-<timestamp>.log in %s", old, dir)
+		}
+		if _, err := os.Stat(old); err != nil {
+			t.Errorf("rotated file: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnRotate not called after exceeding MaxSize")
+	}
+
+	clock := &fakeClock{now: time.Date(2024, 1, 15, 12, 0, 0, 0, time.Local)}
+	dw := newDailyWriter(filepath.Join(dir, "daily.log"), RotateConfig{}, func(old string) { rotated <- old })
+	dw.now = clock.Now
+	defer dw.Close()
+	dw.Write([]byte("day one\n"))
+	clock.Set(time.Date(2024, 1, 16, 12, 0, 0, 0, time.Local))
+	dw.Write([]byte("day two\n"))
+	select {
+	case old := <-rotated:
+		if want := filepath.Join(dir, "daily-2024-01-15.log"); old != want {
+			t.Errorf("daily rotated path = %q; want %q", old, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnRotate not called after date change")
+	}
+}
+
+// 测试 DecompressExistingBackups 在后台解压已有的 .gz 备份，写了一半的 gz 文件保留原样并输出 WARN
+func TestDecompressExistingBackups(t *testing.T) {
+	dir := t.TempDir()
+	gzipFile := func(name, content string, truncate bool) {
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		zw.Write([]byte(content))
+		zw.Close()
+		data := b.Bytes()
+		if truncate {
+			data = data[:len(data)/2]
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 时间戳需在 MaxAge 之内，否则 lumberjack 打开文件时会清理这些备份
+	stamp := func(d time.Duration) string { return "app-" + time.Now().Add(-d).Format("2006-01-02T15-04-05.000") }
+	good, partial := stamp(2*time.Hour), stamp(time.Hour)
+	gzipFile(good+".log.gz", "old line\n", false)
+	gzipFile(partial+".log.gz", strings.Repeat("partial line\n", 100), true)
+	gzipFile("other.gz", "unrelated", false)
+	gzipFile("app-backup.tar.gz", "unrelated", false)
+	gzipFile(strings.TrimPrefix(good, "app-")+".log.gz", "unrelated", false)
+	gzipFile(good+".txt.gz", "unrelated", false)
+
+	noCompress := false
+	log := New(Config{
+		MinLevel:                  INFO,
+		Targets:                   OutputFile,
+		LogPath:                   filepath.Join(dir, "app.log"),
+		FileRotation:              RotateConfig{Compress: &noCompress},
+		DecompressExistingBackups: true,
+	})
+	defer log.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		log.Flush()
+		out, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+		if strings.Contains(string(out), "decompress backup") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no warning about the truncated backup; log = %q", out)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got, err := os.ReadFile(filepath.Join(dir, good+".log")); err != nil || string(got) != "old line\n" {
+		t.Errorf("decompressed backup = %q, %v; want the original content", got, err)
+	}
+	for _, name := range []string{good + ".log.gz", partial + ".log", partial + ".log.tmp", "app-backup.tar", good + ".txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist", name)
+		}
+	}
+	untouched := []string{"other.gz", "app-backup.tar.gz", strings.TrimPrefix(good, "app-") + ".log.gz", good + ".txt.gz"}
+	for _, name := range append([]string{partial + ".log.gz"}, untouched...) {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should be left in place: %v", name, err)
+		}
+	}
+}
+
+// 测试 RotateDaily 时按日期命名的文件（name-日期.ext）压缩出的备份也会被解压
+func TestDecompressDailyBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	daily := "app-" + now.Format("2006-01-02") + "-" + now.Add(-time.Hour).Format("2006-01-02T15-04-05.000")
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Write([]byte("daily line\n"))
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, daily+".log.gz"), b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	compress := true
+	log := New(Config{
+		MinLevel:                  INFO,
+		Targets:                   OutputFile,
+		LogPath:                   filepath.Join(dir, "app.log"),
+		RotateDaily:               true,
+		FileRotation:              RotateConfig{Compress: &compress},
+		DecompressExistingBackups: true,
+	})
+	defer log.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, err := os.ReadFile(filepath.Join(dir, daily+".log"))
+		if err == nil && string(got) == "daily line\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daily backup not decompressed: %q, %v", got, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := os.Stat(filepath.Join(dir, daily+".log.gz")); !os.IsNotExist(err) {
+		t.Errorf("%s.log.gz should be removed", daily)
+	}
+}
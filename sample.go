@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SamplingConfig 对 INFO 及以下（含 DEBUG、TRACE）的日志按消息采样：每个 Tick 内同一等级、同一消息的前 First 条全部输出，
+// 之后每 Thereafter 条输出一条（Thereafter 为 0 时其余全部丢弃）。WARN 及以上从不采样。
+type SamplingConfig struct {
+	Tick       time.Duration // 计数重置周期，默认 1s
+	First      int
+	Thereafter int
+}
+
+// UnmarshalJSON 允许 Tick 写成 "1s" 这样的字符串
+func (c *SamplingConfig) UnmarshalJSON(data []byte) error {
+	type plain SamplingConfig
+	aux := struct {
+		*plain
+		Tick *jsonDuration
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Tick != nil {
+		c.Tick = time.Duration(*aux.Tick)
+	}
+	return nil
+}
+
+// sampleMaxLevel 是会被采样的最高等级
+const sampleMaxLevel = INFO
+
+type sampleKey struct {
+	level Level
+	msg   string
+}
+
+// sampler 的状态只在 start() 协程中访问
+type sampler struct {
+	cfg     SamplingConfig
+	counts  map[sampleKey]int
+	resetAt time.Time
+}
+
+func newSampler(cfg *SamplingConfig) *sampler {
+	if cfg == nil {
+		return nil
+	}
+	s := &sampler{cfg: *cfg, counts: make(map[sampleKey]int)}
+	if s.cfg.Tick <= 0 {
+		s.cfg.Tick = time.Second
+	}
+	return s
+}
+
+// allow 报告这条日志是否应该输出，以日志自身的时间划分周期
+func (s *sampler) allow(msg logMsg) bool {
+	if msg.Level > sampleMaxLevel {
+		return true
+	}
+	if !msg.Time.Before(s.resetAt) {
+		clear(s.counts)
+		s.resetAt = msg.Time.Add(s.cfg.Tick)
+	}
+	key := sampleKey{level: msg.Level, msg: msg.Message}
+	n := s.counts[key] + 1
+	s.counts[key] = n
+	if n <= s.cfg.First {
+		return true
+	}
+	return s.cfg.Thereafter > 0 && (n-s.cfg.First)%s.cfg.Thereafter == 0
+}
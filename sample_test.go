@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试采样只作用于 INFO 及以下，WARN 及以上全部保留
+func TestSamplingKeepsErrors(t *testing.T) {
+	log, buf := newBufferLogger(t, Config{
+		Synchronous: true,
+		MinLevel:    DEBUG,
+		Sampling:    &SamplingConfig{Tick: time.Hour, First: 2, Thereafter: 10},
+	})
+	for i := 0; i < 100; i++ {
+		log.Info("flood")
+		log.Error("flood")
+	}
+	log.Info("other")
+	log.Close()
+
+	out := buf.String()
+	if n := strings.Count(out, "[ERROR]"); n != 100 {
+		t.Errorf("ERROR lines = %d; want 100", n)
+	}
+	// 前 2 条，之后第 12、22、…、92 条
+	if n := strings.Count(out, "[INFO]") - strings.Count(out, "other"); n != 11 {
+		t.Errorf("sampled INFO lines = %d; want 11", n)
+	}
+	if !strings.Contains(out, "other") {
+		t.Errorf("distinct message should be counted separately")
+	}
+	if got := log.Stats().Sampled; got != 89 {
+		t.Errorf("Stats().Sampled = %d; want 89", got)
+	}
+}
+
+// 测试 INFO 及以下的等级会被采样，WARN 及以上不会
+func TestSamplingLevels(t *testing.T) {
+	for _, tc := range []struct {
+		level   Level
+		sampled bool
+	}{{TRACE, true}, {DEBUG, true}, {INFO, true}, {WARN, false}, {ERROR, false}} {
+		s := newSampler(&SamplingConfig{Tick: time.Hour, First: 1})
+		m := logMsg{Level: tc.level, Message: "same", Time: time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)}
+		s.allow(m)
+		if got := !s.allow(m); got != tc.sampled {
+			t.Errorf("%s: second record sampled out = %v; want %v", levelToStr(tc.level), got, tc.sampled)
+		}
+	}
+}
+
+// 测试每个 Tick 周期重新计数
+func TestSamplerTick(t *testing.T) {
+	s := newSampler(&SamplingConfig{Tick: time.Second, First: 1})
+	base := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	msg := func(offset time.Duration) logMsg {
+		return logMsg{Level: DEBUG, Message: "tick", Time: base.Add(offset)}
+	}
+	got := []bool{
+		s.allow(msg(0)),
+		s.allow(msg(500 * time.Millisecond)),
+		s.allow(msg(time.Second)),
+		s.allow(msg(1500 * time.Millisecond)),
+	}
+	want := []bool{true, false, true, false}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("allow #%d = %v; want %v", i, got[i], want[i])
+		}
+	}
+}
+
+// 测试配置文件中的 Sampling 可以把 Tick 写成字符串
+func TestParseSamplingConfig(t *testing.T) {
+	cfg, err := parseConfig([]byte(`{"Sampling": {"Tick": "2s", "First": 5, "Thereafter": 50}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SamplingConfig{Tick: 2 * time.Second, First: 5, Thereafter: 50}
+	if cfg.Sampling == nil || *cfg.Sampling != want {
+		t.Errorf("Sampling = %+v; want %+v", cfg.Sampling, want)
+	}
+}
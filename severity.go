@@ -0,0 +1,36 @@
+package logger
+
+// LogAt 以外部系统的数值严重级别记录日志：先经 Config.SeverityMap 换算为 Level（默认按 syslog 0-7），
+// 再按 MinLevel 等规则过滤。换算为 FATAL 时只记录，不会像 Fatal 那样结束进程。
+func (l *Logger) LogAt(severity int, msg string) {
+	if l.nop {
+		return
+	}
+	l.mu.RLock()
+	m := l.config.SeverityMap
+	l.mu.RUnlock()
+	if m == nil {
+		m = SyslogSeverity
+	}
+	l.log(1, m(severity), msg, nil)
+}
+
+// SyslogSeverity 是 SeverityMap 的默认值，按 RFC 5424 的 syslog 严重级别换算：
+// 0-2（emerg/alert/crit）为 FATAL，3（err）为 ERROR，4（warning）为 WARN，5-6（notice/info）为 INFO，
+// 7（debug）为 DEBUG，大于 7 为 TRACE，负数按 FATAL 处理。
+func SyslogSeverity(severity int) Level {
+	switch {
+	case severity <= 2:
+		return FATAL
+	case severity == 3:
+		return ERROR
+	case severity == 4:
+		return WARN
+	case severity <= 6:
+		return INFO
+	case severity == 7:
+		return DEBUG
+	default:
+		return TRACE
+	}
+}
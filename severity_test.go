@@ -0,0 +1,48 @@
+package logger
+
+import "testing"
+
+// 测试 LogAt 默认按 syslog 严重级别换算等级，并照常按 MinLevel 过滤
+func TestLogAtSyslog(t *testing.T) {
+	log, capture := NewCapturing()
+	log.SetLevel(DEBUG)
+	for _, sev := range []int{0, 2, 3, 4, 5, 6, 7, 9} {
+		log.LogAt(sev, "external")
+	}
+
+	recs := capture.Lines()
+	want := []Level{FATAL, FATAL, ERROR, WARN, INFO, INFO, DEBUG} // severity 9 为 TRACE，被过滤
+	if len(recs) != len(want) {
+		t.Fatalf("got %d records; want %d", len(recs), len(want))
+	}
+	for i, r := range recs {
+		if r.Level != want[i] || r.Message != "external" {
+			t.Errorf("record %d = %v %q; want %v", i, r.Level, r.Message, want[i])
+		}
+	}
+	if recs[0].Caller == "" || recs[0].File != "severity_test.go" {
+		t.Errorf("caller = %q (file %q); want the LogAt call site", recs[0].Caller, recs[0].File)
+	}
+}
+
+// 测试 SeverityMap 替换默认换算
+func TestLogAtCustomMap(t *testing.T) {
+	log, capture := NewCapturing()
+	cfg := log.Config()
+	cfg.SeverityMap = func(s int) Level {
+		if s >= 50 {
+			return ERROR
+		}
+		return INFO
+	}
+	if err := log.Reconfigure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	log.LogAt(10, "low")
+	log.LogAt(60, "high")
+
+	recs := capture.Lines()
+	if len(recs) != 2 || recs[0].Level != INFO || recs[1].Level != ERROR {
+		t.Errorf("records = %+v; want INFO then ERROR", recs)
+	}
+}
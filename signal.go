@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// signalCloseTimeout 是收到信号后等待写完剩余日志的最长时间
+const signalCloseTimeout = 5 * time.Second
+
+// raise 把信号重新发给本进程，测试中替换以免结束测试进程
+var raise = defaultRaise
+
+func defaultRaise(sig os.Signal) {
+	if p, err := os.FindProcess(os.Getpid()); err == nil {
+		_ = p.Signal(sig)
+	}
+}
+
+// ReopenOnSignal 在每次收到 sigs 中任一信号（默认 SIGHUP）时调用 Reopen，失败时输出一条 WARN。
+// 返回的 stop 用于卸载处理器；Logger 关闭后处理器也会自动结束。
+func (l *Logger) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if l.nop {
+		return func() {}
+	}
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	quit := make(chan struct{})
+
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ch:
+				if err := l.Reopen(); err != nil && err != ErrClosed {
+					l.logInternal(WARN, "reopen log files failed", Field{Key: "error", Value: err.Error()})
+				}
+			case <-quit:
+				return
+			case <-l.done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(quit) })
+	}
+}
+
+// CloseOnSignal 在收到 sigs 中任一信号（默认 SIGINT、SIGTERM）时关闭 Logger，
+// 最多等待 5s 写完已入队的日志，然后卸载处理器并把信号重新发给本进程，
+// 使默认行为（通常是退出）或程序自己的信号处理照常发生。返回的 stop 用于提前卸载处理器。
+func (l *Logger) CloseOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	quit := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-ch:
+			ctx, cancel := context.WithTimeout(context.Background(), signalCloseTimeout)
+			_ = l.CloseContext(ctx)
+			cancel()
+			signal.Stop(ch)
+			raise(sig)
+		case <-quit:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(quit)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// 测试收到信号后 Logger 写完剩余日志并关闭，随后把信号重新发出
+func TestCloseOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending os.Interrupt is not supported on windows")
+	}
+	raised := make(chan os.Signal, 1)
+	raise = func(sig os.Signal) { raised <- sig }
+	defer func() { raise = defaultRaise }()
+
+	log, buf := newBufferLogger(t, Config{MinLevel: INFO})
+	stop := log.CloseOnSignal(os.Interrupt)
+	defer stop()
+	log.Info("pending line")
+
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case sig := <-raised:
+		if sig != os.Interrupt {
+			t.Errorf("re-raised %v; want %v", sig, os.Interrupt)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("logger was not closed after the signal")
+	}
+	select {
+	case <-log.done:
+	default:
+		t.Error("logger consumer still running after signal")
+	}
+	if !strings.Contains(buf.String(), "pending line") {
+		t.Errorf("pending log was not flushed: %q", buf.String())
+	}
+}
+
+// 测试 stop 卸载处理器后信号不再关闭 Logger
+func TestCloseOnSignalStop(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO})
+	defer log.Close()
+	stop := log.CloseOnSignal(os.Interrupt)
+	stop()
+	stop()
+	log.Info("still open")
+	log.Flush()
+	select {
+	case <-log.done:
+		t.Error("logger closed after stop")
+	default:
+	}
+}
+
+// 测试 ReopenOnSignal 收到 SIGHUP 后重新打开日志文件
+func TestReopenOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP is not available on windows")
+	}
+	path := filepath.Join(t.TempDir(), "app.log")
+	log := New(Config{MinLevel: INFO, Targets: OutputFile, LogPath: path})
+	defer log.Close()
+	stop := log.ReopenOnSignal()
+	defer stop()
+	log.Info("before")
+	log.Flush()
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	// 信号的处理是异步的，持续写入直到新文件出现
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		log.Info("after")
+		log.Flush()
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("log file was not reopened after SIGHUP")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
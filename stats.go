@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats 是 Logger 内部计数器的快照
+type Stats struct {
+	Messages          map[Level]uint64 // 按等级统计已写出的日志数
+	Dropped           uint64           // 被丢弃的日志数
+	Sampled           uint64           // 被 Sampling 采样掉的日志数
+	Fallback          uint64           // 通道已满时改为直接写入 FallbackWriter 的日志数，不计入 Messages
+	SubscriberDropped uint64           // 因 Subscribe 的订阅者处理不及时而未送达的记录数，不影响其他输出目标
+	QueueLatencyAvg   time.Duration    // 最近 1024 条日志从调用到开始写出的平均耗时，持续升高说明写出跟不上
+	QueueLatencyMax   time.Duration    // 最近 1024 条日志从调用到开始写出的最大耗时
+}
+
+// Stats 返回当前计数器的快照，同源的派生 Logger 共享同一组计数器
+func (l *Logger) Stats() Stats {
+	if l.nop {
+		return Stats{Messages: map[Level]uint64{}}
+	}
+	s := Stats{
+		Messages:          make(map[Level]uint64, numLevels),
+		Dropped:           l.dropped.Load(),
+		Sampled:           l.sampled.Load(),
+		Fallback:          l.fallback.Load(),
+		SubscriberDropped: l.subDropped.Load(),
+	}
+	for i := 0; i < numLevels; i++ {
+		s.Messages[Level(i)] = l.written[i].Load()
+	}
+	s.QueueLatencyAvg, s.QueueLatencyMax = l.latency.snapshot()
+	return s
+}
+
+const latencyWindow = 1024
+
+// latencyTracker 保存最近 latencyWindow 条日志的排队耗时，由 write() 记录、Stats 读取
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples [latencyWindow]time.Duration
+	n       int // 已记录的条数，最多 latencyWindow
+	next    int // 下一条写入的位置
+}
+
+func (t *latencyTracker) observe(d time.Duration) {
+	t.mu.Lock()
+	t.samples[t.next] = d
+	t.next = (t.next + 1) % latencyWindow
+	if t.n < latencyWindow {
+		t.n++
+	}
+	t.mu.Unlock()
+}
+
+func (t *latencyTracker) snapshot() (avg, max time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.n == 0 {
+		return 0, 0
+	}
+	var sum time.Duration
+	for _, d := range t.samples[:t.n] {
+		sum += d
+		if d > max {
+			max = d
+		}
+	}
+	return sum / time.Duration(t.n), max
+}
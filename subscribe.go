@@ -0,0 +1,72 @@
+package logger
+
+import "sync"
+
+// subscriber 是 Subscribe 注册的一个订阅者
+type subscriber struct {
+	ch   chan LogRecord
+	once sync.Once
+}
+
+// Subscribe 返回一个接收此后每条写出的日志的通道，以及取消订阅的函数，适合把实时日志推送到管理界面等进程内消费者。
+// 通道容量为 buffer（至少 1），订阅者处理不及时、通道已满时新记录直接丢弃并计入 Stats().SubscriberDropped，
+// 不会拖慢日志写出。记录中的敏感字段已按 MaskKeys 脱敏。取消订阅或 Logger 关闭后通道被关闭；取消函数可重复调用。
+func (l *Logger) Subscribe(buffer int) (<-chan LogRecord, func()) {
+	if buffer < 1 {
+		buffer = 1
+	}
+	s := &subscriber{ch: make(chan LogRecord, buffer)}
+	if l.nop {
+		close(s.ch)
+		return s.ch, func() {}
+	}
+	l.subsMu.Lock()
+	if l.subsClosed {
+		l.subsMu.Unlock()
+		close(s.ch)
+		return s.ch, func() {}
+	}
+	if l.subs == nil {
+		l.subs = make(map[*subscriber]struct{})
+	}
+	l.subs[s] = struct{}{}
+	l.subCount.Add(1)
+	l.subsMu.Unlock()
+
+	return s.ch, func() {
+		l.subsMu.Lock()
+		defer l.subsMu.Unlock()
+		if _, ok := l.subs[s]; ok {
+			delete(l.subs, s)
+			l.subCount.Add(-1)
+			s.once.Do(func() { close(s.ch) })
+		}
+	}
+}
+
+// publish 把一条已写出的日志非阻塞地发送给所有订阅者，由 write() 调用
+func (l *Logger) publish(msg logMsg) {
+	rec := msg.record()
+	rec.Fields = l.maskFields(rec.Fields)
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	for s := range l.subs {
+		select {
+		case s.ch <- rec:
+		default:
+			l.subDropped.Add(1)
+		}
+	}
+}
+
+// closeSubscribers 在 Logger 关闭时关闭所有订阅通道，之后的 Subscribe 返回已关闭的通道
+func (l *Logger) closeSubscribers() {
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	l.subsClosed = true
+	for s := range l.subs {
+		s.once.Do(func() { close(s.ch) })
+	}
+	l.subs = nil
+	l.subCount.Store(0)
+}
@@ -0,0 +1,67 @@
+package logger
+
+import "testing"
+
+// drain 读出通道中现有的记录，通道关闭时 closed 为 true
+func drain(ch <-chan LogRecord) (recs []LogRecord, closed bool) {
+	for {
+		select {
+		case r, ok := <-ch:
+			if !ok {
+				return recs, true
+			}
+			recs = append(recs, r)
+		default:
+			return recs, false
+		}
+	}
+}
+
+// 测试多个订阅者都能收到记录，慢订阅者的溢出单独计数，取消订阅与关闭后通道关闭
+func TestSubscribe(t *testing.T) {
+	log, _ := newBufferLogger(t, Config{MinLevel: INFO, MaskKeys: []string{"password"}})
+	fast, unsubFast := log.Subscribe(10)
+	slow, _ := log.Subscribe(1)
+
+	log.Info("first")
+	log.Infow("second", "password", "hunter2")
+	log.Debug("filtered")
+	log.Flush()
+
+	recs, closed := drain(fast)
+	if closed || len(recs) != 2 || recs[0].Message != "first" || recs[1].Message != "second" {
+		t.Fatalf("fast subscriber got %+v (closed %v); want first and second", recs, closed)
+	}
+	if v := fieldValue(recs[1].Fields, "password"); v != "***" {
+		t.Errorf("password field = %v; want it masked", v)
+	}
+	if recs[0].Level != INFO || recs[0].File != "subscribe_test.go" {
+		t.Errorf("record = %+v; want INFO from subscribe_test.go", recs[0])
+	}
+	if recs, _ := drain(slow); len(recs) != 1 || recs[0].Message != "first" {
+		t.Errorf("slow subscriber got %+v; want only first", recs)
+	}
+	if got := log.Stats().SubscriberDropped; got != 1 {
+		t.Errorf("SubscriberDropped = %d; want 1", got)
+	}
+
+	unsubFast()
+	unsubFast()
+	log.Info("third")
+	log.Flush()
+	if recs, closed := drain(fast); len(recs) != 0 || !closed {
+		t.Errorf("after unsubscribe got %+v (closed %v); want a closed, empty channel", recs, closed)
+	}
+	if recs, _ := drain(slow); len(recs) != 1 || recs[0].Message != "third" {
+		t.Errorf("slow subscriber got %+v; want third", recs)
+	}
+
+	log.Close()
+	if _, closed := drain(slow); !closed {
+		t.Error("subscriber channel not closed after Close")
+	}
+	late, _ := log.Subscribe(1)
+	if _, closed := drain(late); !closed {
+		t.Error("Subscribe after Close should return a closed channel")
+	}
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// writeSummary 在 SummaryOnClose 开启时写出一条按等级汇总本次运行已写出日志数的 INFO，
+// 如 "session summary: trace=0 debug=12 info=340 warn=5 error=2 fatal=0"。
+// 只在关闭时、写入器关闭之前调用，与 reportDropped 一样不经过通道，也不受 MinLevel 限制。
+func (l *Logger) writeSummary() {
+	if !l.config.SummaryOnClose {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString("session summary:")
+	for i := 0; i < numLevels; i++ {
+		name := strings.ToLower(levelToStr(Level(i)))
+		n := l.written[i].Load()
+		fmt.Fprintf(&sb, " %s=%d", name, n)
+	}
+	if n := l.dropped.Load(); n > 0 {
+		fmt.Fprintf(&sb, " dropped=%d", n)
+	}
+	l.pending.Add(1)
+	l.write(logMsg{
+		Level:   INFO,
+		Message: sb.String(),
+		Time:    time.Now(),
+		Caller:  "logger",
+	})
+}
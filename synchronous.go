@@ -0,0 +1,50 @@
+package logger
+
+// 同步模式（Config.Synchronous）下没有后台协程：日志在调用方协程中直接写出，
+// 原本由 start() 串行处理的写入、控制请求与关闭改为在 syncMu 下执行。
+
+// writeSync 在调用方协程中写出一条日志，与 Close 并发时要么写出要么计为丢弃
+func (l *Logger) writeSync(msg logMsg) {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	if l.closed.Load() {
+		l.countDrop()
+		return
+	}
+	l.write(msg)
+}
+
+// controlSync 对应 start() 中的 ctrl 分支；同步模式下没有待写出的日志，Flush 直接返回
+func (l *Logger) controlSync(req controlReq) {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	if l.closed.Load() {
+		*req.err = ErrClosed
+		return
+	}
+	if req.cfg != nil {
+		l.applyConfig(*req.cfg, req.warnings)
+	}
+	if req.allowed != nil {
+		l.applyAllowed(req.allowed)
+	}
+	if req.sync {
+		l.unsynced = true
+		l.syncFiles()
+	}
+	if req.reopen {
+		*req.err = l.reopenFiles()
+	}
+}
+
+// closeSync 关闭写入器；之后的日志与控制请求都会看到 closed
+func (l *Logger) closeSync() {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	l.closed.Store(true)
+	close(l.quit)
+	l.writeSummary()
+	l.closeWriters()
+	l.closeSubscribers()
+	close(l.done)
+}
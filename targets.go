@@ -0,0 +1,25 @@
+package logger
+
+// To 返回一个派生 Logger，它输出的日志只写入 targets，代替配置中的 Targets，
+// 例如 log.To(OutputConsole|OutputFile).Info("audit") 强制同时写入控制台与文件，
+// log.To(OutputConsole).Debug(...) 只输出到控制台。配置中未启用文件输出时按 LogPath 按需打开文件；
+// 事件日志与 os_log 只有在配置中启用时才可用。白名单文件、JSONLPath 与 Hooks 等不受影响。
+func (l *Logger) To(targets OutputTarget) *Logger {
+	if l.nop {
+		return l
+	}
+	child := *l
+	child.targets = &targets
+	return &child
+}
+
+// openFileLazily 在 To 要求写入文件而配置中未启用文件输出时打开 LogPath，只在 write() 中调用
+func (l *Logger) openFileLazily() {
+	if l.config.LogPath == "" {
+		return
+	}
+	w := newMainWriter(l.config)
+	l.mu.Lock()
+	l.fileLogger = w
+	l.mu.Unlock()
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试 To 覆盖单条日志的输出目标
+func TestToOverridesTargets(t *testing.T) {
+	console := &syncBuffer{}
+	log, file := newBufferLogger(t, Config{MinLevel: INFO, ConsoleWriter: console})
+	log.config.Targets |= OutputConsole
+	log.To(OutputConsole).Info("console only")
+	log.To(OutputFile).Info("file only")
+	log.To(OutputNone).Info("nowhere")
+	log.Info("both")
+	log.Close()
+
+	for _, c := range []struct {
+		name, out string
+		want      []string
+		unwanted  []string
+	}{
+		{"console", console.String(), []string{"console only", "both"}, []string{"file only", "nowhere"}},
+		{"file", file.String(), []string{"file only", "both"}, []string{"console only", "nowhere"}},
+	} {
+		for _, w := range c.want {
+			if !strings.Contains(c.out, w) {
+				t.Errorf("%s output %q missing %q", c.name, c.out, w)
+			}
+		}
+		for _, u := range c.unwanted {
+			if strings.Contains(c.out, u) {
+				t.Errorf("%s output %q should not contain %q", c.name, c.out, u)
+			}
+		}
+	}
+}
+
+// 测试配置中未启用文件输出时，To 要求写入文件的日志按 LogPath 打开文件写入
+func TestToOpensFileOnDemand(t *testing.T) {
+	console := &syncBuffer{}
+	path := filepath.Join(t.TempDir(), "audit", "app.log")
+	log := New(Config{MinLevel: INFO, Targets: OutputConsole, LogPath: path, ConsoleWriter: console})
+	log.Info("console line")
+	log.To(OutputConsole | OutputFile).Info("audit event")
+	log.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "audit event") || strings.Contains(string(data), "console line") {
+		t.Errorf("file = %q; want only the audit event", data)
+	}
+	if !strings.Contains(console.String(), "audit event") || !strings.Contains(console.String(), "console line") {
+		t.Errorf("console = %q; want both lines", console.String())
+	}
+}